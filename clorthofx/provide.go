@@ -148,14 +148,34 @@ func newRefresher(in RefresherIn) (r clortho.Refresher, err error) {
 
 // ResolverIn enumerates the set of components involved in the creation
 // of a clortho.Resolver.
-type ResolverIn RefresherIn
+type ResolverIn struct {
+	fx.In
+
+	// KeyRing is the cache for resolved keys.  This will be either supplied from the
+	// enclosing application or internally created within this module.
+	KeyRing clortho.KeyRing
+
+	// Refresher is refreshed when a key is not found, if Config.Resolve.RefreshOnMiss is set.
+	Refresher clortho.Refresher `optional:"true"`
+
+	Fetcher         clortho.Fetcher
+	Config          clortho.Config           `optional:"true"`
+	ZapListener     *clorthozap.Listener     `optional:"true"`
+	MetricsListener *clorthometrics.Listener `optional:"true"`
+}
 
 func newResolver(in ResolverIn) (r clortho.Resolver, err error) {
-	r, err = clortho.NewResolver(
+	options := []clortho.ResolverOption{
 		clortho.WithFetcher(in.Fetcher),
 		clortho.WithKeyRing(in.KeyRing),
 		clortho.WithConfig(in.Config),
-	)
+	}
+
+	if in.Config.Resolve.RefreshOnMiss && in.Refresher != nil {
+		options = append(options, clortho.WithRefreshOnMiss(in.Refresher))
+	}
+
+	r, err = clortho.NewResolver(options...)
 
 	if err == nil {
 		if in.ZapListener != nil {
@@ -199,7 +219,9 @@ func newKeyAccessor(kr clortho.KeyRing) clortho.KeyAccessor {
 //     This will be non-nil only if a *touchstone.Factory is supplied.  If non-nil, it will
 //
 //   - clortho.Refresher
-//     The refresher will be bound to the application lifecycle.
+//     The refresher will be bound to the application lifecycle.  If any refresh sources are
+//     required, application startup blocks until the key ring holds their keys, subject to
+//     the application's start timeout.
 //
 //   - clortho.Resolver
 //
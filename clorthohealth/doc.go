@@ -0,0 +1,7 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clorthohealth exposes the status of clortho components over HTTP.
+// Primarily, this is a handler that reports the state of a Refresher as JSON,
+// suitable for use as a readiness probe.
+package clorthohealth
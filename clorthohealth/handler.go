@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clorthohealth
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/xmidt-org/clortho"
+)
+
+// Meta is the JSON representation of a clortho.ContentMeta.
+type Meta struct {
+	Format               string     `json:"format,omitempty"`
+	TTL                  string     `json:"ttl,omitempty"`
+	LastModified         *time.Time `json:"lastModified,omitempty"`
+	ETag                 string     `json:"etag,omitempty"`
+	StaleWhileRevalidate string     `json:"staleWhileRevalidate,omitempty"`
+	StaleIfError         string     `json:"staleIfError,omitempty"`
+	Revalidate           bool       `json:"revalidate,omitempty"`
+}
+
+// Source is the JSON representation of a clortho.SourceStatus.
+type Source struct {
+	URI         string     `json:"uri"`
+	Required    bool       `json:"required"`
+	Synced      bool       `json:"synced"`
+	LastAttempt *time.Time `json:"lastAttempt,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	KeyIDs      []string   `json:"keyIDs"`
+	Meta        Meta       `json:"meta"`
+	NextRefresh *time.Time `json:"nextRefresh,omitempty"`
+}
+
+// Status is the JSON representation of a clortho.RefresherStatus.  This is
+// the body written by Handler.
+type Status struct {
+	Ready   bool     `json:"ready"`
+	Running bool     `json:"running"`
+	Sources []Source `json:"sources"`
+}
+
+// optionalTime returns nil for the zero time, so that unset times are omitted.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// NewStatus converts a clortho.RefresherStatus into its JSON representation.
+func NewStatus(rs clortho.RefresherStatus) Status {
+	s := Status{
+		Ready:   rs.Ready(),
+		Running: rs.Running,
+		Sources: make([]Source, 0, len(rs.Sources)),
+	}
+
+	for _, ss := range rs.Sources {
+		source := Source{
+			URI:         ss.URI,
+			Required:    ss.Required,
+			Synced:      ss.Synced(),
+			LastAttempt: optionalTime(ss.LastAttempt),
+			LastSuccess: optionalTime(ss.LastSuccess),
+			KeyIDs:      ss.KeyIDs,
+			Meta: Meta{
+				Format:       ss.Meta.Format,
+				LastModified: optionalTime(ss.Meta.LastModified),
+				ETag:         ss.Meta.ETag,
+				Revalidate:   ss.Meta.Revalidate,
+			},
+			NextRefresh: optionalTime(ss.NextRefresh),
+		}
+
+		if source.KeyIDs == nil {
+			source.KeyIDs = []string{}
+		}
+
+		if ss.LastError != nil {
+			source.LastError = ss.LastError.Error()
+		}
+
+		if ss.Meta.TTL > 0 {
+			source.Meta.TTL = ss.Meta.TTL.String()
+		}
+
+		if ss.Meta.StaleWhileRevalidate > 0 {
+			source.Meta.StaleWhileRevalidate = ss.Meta.StaleWhileRevalidate.String()
+		}
+
+		if ss.Meta.StaleIfError > 0 {
+			source.Meta.StaleIfError = ss.Meta.StaleIfError.String()
+		}
+
+		s.Sources = append(s.Sources, source)
+	}
+
+	return s
+}
+
+// Handler is an http.Handler that writes the status of a clortho.Refresher as JSON.
+// The response code is http.StatusOK when the Refresher is ready, i.e. it is running
+// and all required sources have synced.  Otherwise, the response code is
+// http.StatusServiceUnavailable.  In either case, the body describes each source.
+type Handler struct {
+	refresher clortho.Refresher
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler constructs a *Handler that reports on the given Refresher.
+func NewHandler(r clortho.Refresher) *Handler {
+	return &Handler{
+		refresher: r,
+	}
+}
+
+// ServeHTTP writes the current status of the Refresher.
+func (h *Handler) ServeHTTP(response http.ResponseWriter, _ *http.Request) {
+	s := NewStatus(h.refresher.Status())
+	body, err := json.Marshal(s)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	code := http.StatusOK
+	if !s.Ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("Cache-Control", "no-store")
+	response.WriteHeader(code)
+	response.Write(body)
+}
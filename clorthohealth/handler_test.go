@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clorthohealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/clortho"
+)
+
+// stubRefresher is a clortho.Refresher that simply returns a fixed status.
+type stubRefresher struct {
+	clortho.Refresher
+	status clortho.RefresherStatus
+}
+
+func (sr stubRefresher) Status() clortho.RefresherStatus {
+	return sr.status
+}
+
+type HandlerSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func (suite *HandlerSuite) SetupSuite() {
+	suite.now = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *HandlerSuite) serve(status clortho.RefresherStatus) (*httptest.ResponseRecorder, Status) {
+	var (
+		h        = NewHandler(stubRefresher{status: status})
+		response = httptest.NewRecorder()
+		request  = httptest.NewRequestWithContext(context.Background(), "GET", "/ready", nil)
+		body     Status
+	)
+
+	h.ServeHTTP(response, request)
+	suite.Equal("application/json", response.Header().Get("Content-Type"))
+	suite.Require().NoError(
+		json.Unmarshal(response.Body.Bytes(), &body),
+	)
+
+	return response, body
+}
+
+func (suite *HandlerSuite) TestNotRunning() {
+	response, body := suite.serve(clortho.RefresherStatus{
+		Sources: []clortho.SourceStatus{
+			{URI: "http://getkeys.com/keys"},
+		},
+	})
+
+	suite.Equal(http.StatusServiceUnavailable, response.Code)
+	suite.False(body.Ready)
+	suite.False(body.Running)
+	suite.Require().Len(body.Sources, 1)
+	suite.Equal("http://getkeys.com/keys", body.Sources[0].URI)
+	suite.False(body.Sources[0].Synced)
+	suite.Nil(body.Sources[0].LastAttempt)
+	suite.Empty(body.Sources[0].KeyIDs)
+}
+
+func (suite *HandlerSuite) TestRequiredNotSynced() {
+	response, body := suite.serve(clortho.RefresherStatus{
+		Running: true,
+		Sources: []clortho.SourceStatus{
+			{
+				URI:         "http://getkeys.com/keys",
+				Required:    true,
+				LastAttempt: suite.now,
+				LastError:   errors.New("expected"),
+				NextRefresh: suite.now.Add(time.Minute),
+			},
+		},
+	})
+
+	suite.Equal(http.StatusServiceUnavailable, response.Code)
+	suite.False(body.Ready)
+	suite.True(body.Running)
+	suite.Require().Len(body.Sources, 1)
+	suite.True(body.Sources[0].Required)
+	suite.False(body.Sources[0].Synced)
+	suite.Equal("expected", body.Sources[0].LastError)
+	suite.Require().NotNil(body.Sources[0].LastAttempt)
+	suite.True(suite.now.Equal(*body.Sources[0].LastAttempt))
+	suite.Nil(body.Sources[0].LastSuccess)
+}
+
+func (suite *HandlerSuite) TestReady() {
+	response, body := suite.serve(clortho.RefresherStatus{
+		Running: true,
+		Sources: []clortho.SourceStatus{
+			{
+				URI:         "http://getkeys.com/keys",
+				Required:    true,
+				LastAttempt: suite.now,
+				LastSuccess: suite.now,
+				KeyIDs:      []string{"A", "B"},
+				Meta: clortho.ContentMeta{
+					Format: clortho.MediaTypeJWKSet,
+					TTL:    time.Hour,
+					ETag:   `"v1"`,
+
+					StaleWhileRevalidate: time.Minute,
+					StaleIfError:         2 * time.Minute,
+					Revalidate:           true,
+				},
+				NextRefresh: suite.now.Add(time.Hour),
+			},
+			{
+				// an optional source that hasn't synced doesn't affect readiness
+				URI:         "http://another.com/keys",
+				LastAttempt: suite.now,
+				LastError:   errors.New("expected"),
+			},
+		},
+	})
+
+	suite.Equal(http.StatusOK, response.Code)
+	suite.True(body.Ready)
+	suite.Require().Len(body.Sources, 2)
+
+	synced := body.Sources[0]
+	suite.True(synced.Synced)
+	suite.Empty(synced.LastError)
+	suite.Equal([]string{"A", "B"}, synced.KeyIDs)
+	suite.Equal(clortho.MediaTypeJWKSet, synced.Meta.Format)
+	suite.Equal("1h0m0s", synced.Meta.TTL)
+	suite.Equal(`"v1"`, synced.Meta.ETag)
+	suite.Equal("1m0s", synced.Meta.StaleWhileRevalidate)
+	suite.Equal("2m0s", synced.Meta.StaleIfError)
+	suite.True(synced.Meta.Revalidate)
+	suite.Require().NotNil(synced.NextRefresh)
+	suite.True(suite.now.Add(time.Hour).Equal(*synced.NextRefresh))
+
+	suite.False(body.Sources[1].Synced)
+	suite.Equal("expected", body.Sources[1].LastError)
+}
+
+func TestHandler(t *testing.T) {
+	suite.Run(t, new(HandlerSuite))
+}
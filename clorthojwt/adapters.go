@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clorthojwt
+
+import (
+	"context"
+
+	gjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+// stringHeader returns a string header from a golang-jwt token, or the empty
+// string if the header is missing or not a string.
+func stringHeader(t *gjwt.Token, name string) (v string) {
+	v, _ = t.Header[name].(string)
+	return
+}
+
+// Keyfunc adapts this Verifier to a github.com/golang-jwt/jwt/v5 Keyfunc.  The returned
+// closure looks up keys with KeyFor, using the given context for any Resolver calls, and
+// returns the public portion of the key.
+//
+// Only key selection is handled by the closure.  Claims validation is done by golang-jwt
+// according to its own parser options, not this Verifier's WithIssuer, WithAudience, or WithLeeway.
+func (v *Verifier) Keyfunc(ctx context.Context) gjwt.Keyfunc {
+	return func(t *gjwt.Token) (interface{}, error) {
+		k, err := v.KeyFor(ctx, KeyHint{
+			KeyID:          stringHeader(t, "kid"),
+			Algorithm:      stringHeader(t, "alg"),
+			Thumbprint:     stringHeader(t, "x5t"),
+			ThumbprintS256: stringHeader(t, "x5t#S256"),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return k.Public(), nil
+	}
+}
+
+// KeyProvider adapts this Verifier to a lestrrat-go/jwx jws.KeyProvider for use with
+// jws.WithKeyProvider.  Keys are looked up with KeyFor for each signature, using the
+// context passed to jws.Verify via jws.WithContext.
+//
+// Any error from KeyFor is returned from FetchKeys, which causes jws.Verify to fail.
+func (v *Verifier) KeyProvider() jws.KeyProvider {
+	return jws.KeyProviderFunc(func(ctx context.Context, sink jws.KeySink, sig *jws.Signature, _ *jws.Message) error {
+		headers := sig.ProtectedHeaders()
+		k, err := v.KeyFor(ctx, hintFromJWS(headers))
+		if err != nil {
+			return err
+		}
+
+		sink.Key(headers.Algorithm(), k.Public())
+		return nil
+	})
+}
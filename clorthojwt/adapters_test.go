@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clorthojwt
+
+import (
+	"context"
+	"testing"
+
+	gjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/clortho"
+)
+
+type contextKey struct{}
+
+// contextResolver is a clortho.Resolver that records the context passed to Resolve.
+type contextResolver struct {
+	clortho.Resolver
+	key     clortho.Key
+	context *context.Context
+}
+
+func (cr contextResolver) Resolve(ctx context.Context, _ string) (clortho.Key, error) {
+	*cr.context = ctx
+	return cr.key, nil
+}
+
+type AdaptersSuite struct {
+	VerifierSuite
+}
+
+// signWithHeaders creates a JWS with the given protected headers.
+func (suite *AdaptersSuite) signWithHeaders(signer jwk.Key, headers map[string]interface{}) []byte {
+	hdrs := jws.NewHeaders()
+	for name, value := range headers {
+		suite.Require().NoError(hdrs.Set(name, value))
+	}
+
+	signed, err := jws.Sign(
+		[]byte(`{"sub":"subject"}`),
+		jws.WithKey(jwa.ES256, signer, jws.WithProtectedHeaders(hdrs)),
+	)
+
+	suite.Require().NoError(err)
+	return signed
+}
+
+func (suite *AdaptersSuite) TestKeyfunc() {
+	var (
+		v     = suite.newVerifier(WithKeyAccessor(suite.keyRing))
+		token = suite.sign(suite.privateKey, jwa.ES256, nil)
+	)
+
+	t, err := gjwt.Parse(string(token), v.Keyfunc(context.Background()))
+	suite.Require().NoError(err)
+	suite.True(t.Valid)
+
+	other, _ := suite.newKeyPair("unknown", nil)
+	_, err = gjwt.Parse(string(suite.sign(other, jwa.ES256, nil)), v.Keyfunc(context.Background()))
+
+	var ukie *UnknownKeyIDError
+	suite.ErrorAs(err, &ukie)
+}
+
+func (suite *AdaptersSuite) TestKeyfuncContext() {
+	var (
+		ctx      = context.WithValue(context.Background(), contextKey{}, "value")
+		captured context.Context
+
+		v = suite.newVerifier(WithResolver(contextResolver{
+			key:     suite.publicKey,
+			context: &captured,
+		}))
+	)
+
+	_, err := gjwt.Parse(string(suite.sign(suite.privateKey, jwa.ES256, nil)), v.Keyfunc(ctx))
+	suite.Require().NoError(err)
+	suite.Require().NotNil(captured)
+	suite.Equal("value", captured.Value(contextKey{}))
+}
+
+func (suite *AdaptersSuite) TestKeyProvider() {
+	var (
+		ctx      = context.WithValue(context.Background(), contextKey{}, "value")
+		captured context.Context
+
+		v = suite.newVerifier(WithResolver(contextResolver{
+			key:     suite.publicKey,
+			context: &captured,
+		}))
+	)
+
+	payload, err := jws.Verify(
+		suite.sign(suite.privateKey, jwa.ES256, nil),
+		jws.WithKeyProvider(v.KeyProvider()),
+		jws.WithContext(ctx),
+	)
+
+	suite.Require().NoError(err)
+	suite.NotEmpty(payload)
+	suite.Require().NotNil(captured)
+	suite.Equal("value", captured.Value(contextKey{}))
+
+	other, _ := suite.newKeyPair("test", nil)
+	_, err = jws.Verify(
+		suite.sign(other, jwa.ES256, nil),
+		jws.WithKeyProvider(v.KeyProvider()),
+	)
+
+	suite.Error(err)
+}
+
+func (suite *AdaptersSuite) TestThumbprintLookup() {
+	// the tokens carry no kid, so the key must be found by its certificate
+	private, public := suite.newKeyPair("thumbprinted", map[string]interface{}{
+		jwk.X509CertThumbprintS256Key: "s256",
+		jwk.X509CertThumbprintKey:     "sha1",
+	})
+
+	suite.Require().NoError(private.Remove(jwk.KeyIDKey))
+
+	var (
+		captured context.Context
+
+		v = suite.newVerifier(
+			WithKeyAccessor(clortho.NewKeyRing(public)),
+			WithResolver(contextResolver{
+				key:     public,
+				context: &captured,
+			}),
+		)
+	)
+
+	for _, headers := range []map[string]interface{}{
+		{jws.X509CertThumbprintS256Key: "s256"},
+		{jws.X509CertThumbprintKey: "sha1"},
+		{jws.X509CertThumbprintS256Key: "s256", jws.X509CertThumbprintKey: "sha1"},
+	} {
+		_, k, err := v.VerifyJWS(context.Background(), suite.signWithHeaders(private, headers))
+		suite.Require().NoError(err)
+		suite.Equal(public, k)
+	}
+
+	for _, headers := range []map[string]interface{}{
+		{jws.X509CertThumbprintS256Key: "s256", jws.X509CertThumbprintKey: "unexpected"},
+		{jws.X509CertThumbprintS256Key: "unexpected"},
+
+		// a thumbprint is never treated as a kid
+		{jws.X509CertThumbprintS256Key: "thumbprinted"},
+	} {
+		_, _, err := v.VerifyJWS(context.Background(), suite.signWithHeaders(private, headers))
+		suite.ErrorIs(err, ErrUnknownThumbprint)
+	}
+
+	_, _, err := v.VerifyJWS(context.Background(), suite.signWithHeaders(private, nil))
+	suite.ErrorIs(err, ErrMissingKeyID)
+
+	// thumbprints are never resolved
+	suite.Nil(captured)
+}
+
+func (suite *AdaptersSuite) TestThumbprintMismatch() {
+	var (
+		private, public = suite.newKeyPair("thumbprinted", map[string]interface{}{
+			jwk.X509CertThumbprintS256Key: "expected",
+		})
+
+		v = suite.newVerifier(WithKeyAccessor(clortho.NewKeyRing(public)))
+	)
+
+	_, _, err := v.VerifyJWS(
+		context.Background(),
+		suite.signWithHeaders(private, map[string]interface{}{jws.X509CertThumbprintS256Key: "expected"}),
+	)
+
+	suite.NoError(err)
+
+	_, _, err = v.VerifyJWS(
+		context.Background(),
+		suite.signWithHeaders(private, map[string]interface{}{jws.X509CertThumbprintS256Key: "unexpected"}),
+	)
+
+	suite.ErrorIs(err, ErrKeyMismatch)
+}
+
+func TestAdapters(t *testing.T) {
+	suite.Run(t, new(AdaptersSuite))
+}
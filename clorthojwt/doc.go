@@ -0,0 +1,11 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clorthojwt verifies JWS messages and JWTs using keys managed by clortho.
+// Keys are looked up by the kid header in a clortho.KeyAccessor, falling back to an
+// optional clortho.Resolver.  Without a kid, the KeyAccessor is searched for a key whose
+// certificate matches the x5t#S256 or x5t header.
+//
+// A Verifier can also supply keys to other libraries:  Verifier.Keyfunc adapts it to
+// github.com/golang-jwt/jwt/v5, and Verifier.KeyProvider adapts it to lestrrat-go/jwx.
+package clorthojwt
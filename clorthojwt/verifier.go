@@ -0,0 +1,482 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clorthojwt
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/xmidt-org/chronon"
+	"github.com/xmidt-org/clortho"
+	"go.uber.org/multierr"
+)
+
+var (
+	// ErrNoKeys indicates that a Verifier was configured with neither a KeyAccessor nor a Resolver.
+	ErrNoKeys = errors.New("A KeyAccessor or Resolver is required")
+
+	// ErrMissingKeyID indicates that a JWS had neither a kid header nor a certificate
+	// thumbprint header that could identify the key.
+	ErrMissingKeyID = errors.New("No kid or x5t header was present")
+
+	// ErrUnknownThumbprint indicates that a JWS had no kid header and that no key had a
+	// certificate matching its thumbprint headers.
+	ErrUnknownThumbprint = errors.New("No key has a certificate with that thumbprint")
+
+	// ErrMalformed indicates that a token could not be parsed.
+	ErrMalformed = errors.New("The token is malformed")
+
+	// ErrAlgorithmNotAllowed indicates that the alg header is not in the Verifier's allowlist.
+	ErrAlgorithmNotAllowed = errors.New("That signature algorithm is not allowed")
+
+	// ErrKeyMismatch indicates that a key's type, algorithm, usage, or operations are
+	// inconsistent with the signature algorithm.
+	ErrKeyMismatch = errors.New("The key cannot be used with that signature algorithm")
+
+	// ErrInvalidSignature indicates that a signature did not verify.
+	ErrInvalidSignature = errors.New("The signature is invalid")
+
+	// ErrTokenExpired indicates that a JWT's exp claim has passed.
+	ErrTokenExpired = errors.New("The token has expired")
+
+	// ErrTokenNotYetValid indicates that a JWT's nbf claim has not yet arrived.
+	ErrTokenNotYetValid = errors.New("The token is not yet valid")
+
+	// ErrInvalidIssuer indicates that a JWT's iss claim is not the expected issuer.
+	ErrInvalidIssuer = errors.New("The token has an unexpected issuer")
+
+	// ErrInvalidAudience indicates that a JWT's aud claim does not contain the expected audience.
+	ErrInvalidAudience = errors.New("The token has an unexpected audience")
+
+	// ErrInvalidClaims indicates that a JWT's claims failed validation for some other reason.
+	ErrInvalidClaims = errors.New("The token's claims are invalid")
+)
+
+// UnknownKeyIDError indicates that no key could be found for a kid.
+type UnknownKeyIDError struct {
+	// KeyID is the kid that could not be found.
+	KeyID string
+
+	// Err is the error from the Resolver, if any.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (ukie *UnknownKeyIDError) Error() string {
+	if ukie.Err != nil {
+		return fmt.Sprintf("Unknown key ID [%s]: %s", ukie.KeyID, ukie.Err)
+	}
+
+	return fmt.Sprintf("Unknown key ID [%s]", ukie.KeyID)
+}
+
+// Unwrap returns the underlying error, if any.
+func (ukie *UnknownKeyIDError) Unwrap() error {
+	return ukie.Err
+}
+
+// DefaultAlgorithms returns the signature algorithms a Verifier allows by default.
+// These are the asymmetric algorithms.  HMAC algorithms must be explicitly allowed
+// via WithAlgorithms.
+func DefaultAlgorithms() []string {
+	return []string{
+		jwa.RS256.String(), jwa.RS384.String(), jwa.RS512.String(),
+		jwa.PS256.String(), jwa.PS384.String(), jwa.PS512.String(),
+		jwa.ES256.String(), jwa.ES384.String(), jwa.ES512.String(),
+		jwa.EdDSA.String(),
+	}
+}
+
+// keyTypes maps signature algorithms onto the JWK key type each requires.
+var keyTypes = map[jwa.SignatureAlgorithm]jwa.KeyType{
+	jwa.RS256:  jwa.RSA,
+	jwa.RS384:  jwa.RSA,
+	jwa.RS512:  jwa.RSA,
+	jwa.PS256:  jwa.RSA,
+	jwa.PS384:  jwa.RSA,
+	jwa.PS512:  jwa.RSA,
+	jwa.ES256:  jwa.EC,
+	jwa.ES384:  jwa.EC,
+	jwa.ES512:  jwa.EC,
+	jwa.ES256K: jwa.EC,
+	jwa.EdDSA:  jwa.OKP,
+	jwa.HS256:  jwa.OctetSeq,
+	jwa.HS384:  jwa.OctetSeq,
+	jwa.HS512:  jwa.OctetSeq,
+}
+
+// CheckKey verifies that a key is consistent with a signature algorithm.  The key's
+// type must match the algorithm.  If the key declares an algorithm, usage, or operations,
+// those must also permit verifying signatures with alg.  Any inconsistency is reported
+// as an error wrapping ErrKeyMismatch.
+func CheckKey(k clortho.Key, alg string) error {
+	kty, ok := keyTypes[jwa.SignatureAlgorithm(alg)]
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: unsupported algorithm %s", ErrKeyMismatch, alg)
+
+	case k.KeyType() != kty.String():
+		return fmt.Errorf("%w: key type %s cannot be used with %s", ErrKeyMismatch, k.KeyType(), alg)
+
+	case len(k.Algorithm()) > 0 && k.Algorithm() != alg:
+		return fmt.Errorf("%w: key is declared for %s, not %s", ErrKeyMismatch, k.Algorithm(), alg)
+
+	case len(k.KeyUsage()) > 0 && k.KeyUsage() != "sig":
+		return fmt.Errorf("%w: key usage is %s", ErrKeyMismatch, k.KeyUsage())
+
+	case len(k.KeyOperations()) > 0 && len(clortho.Keys{k}.Filter(clortho.ByKeyOperation("verify"))) == 0:
+		return fmt.Errorf("%w: key operations do not include verify", ErrKeyMismatch)
+
+	default:
+		return nil
+	}
+}
+
+// VerifierOption is a configurable option passed to NewVerifier.
+type VerifierOption interface {
+	applyToVerifier(*Verifier) error
+}
+
+type verifierOptionFunc func(*Verifier) error
+
+func (vof verifierOptionFunc) applyToVerifier(v *Verifier) error {
+	return vof(v)
+}
+
+// WithKeyAccessor sets the keys consulted first when looking up a kid.  These are
+// also the only keys searched by certificate thumbprint.  Typically, this will be a clortho.KeyRing.
+func WithKeyAccessor(ka clortho.KeyAccessor) VerifierOption {
+	return verifierOptionFunc(func(v *Verifier) error {
+		v.keys = ka
+		return nil
+	})
+}
+
+// WithResolver sets the Resolver used when a kid is not in the KeyAccessor.
+// By default, no Resolver is used.
+func WithResolver(r clortho.Resolver) VerifierOption {
+	return verifierOptionFunc(func(v *Verifier) error {
+		v.resolver = r
+		return nil
+	})
+}
+
+// WithAlgorithms sets the allowlist of signature algorithms.  This option replaces
+// any previous allowlist.  By default, DefaultAlgorithms are allowed.  The none
+// algorithm is never allowed.
+func WithAlgorithms(algs ...string) VerifierOption {
+	return verifierOptionFunc(func(v *Verifier) (err error) {
+		v.algorithms = make(map[string]bool, len(algs))
+		for _, alg := range algs {
+			if _, ok := keyTypes[jwa.SignatureAlgorithm(alg)]; !ok {
+				err = multierr.Append(err, fmt.Errorf("Unsupported signature algorithm: %s", alg))
+				continue
+			}
+
+			v.algorithms[alg] = true
+		}
+
+		return
+	})
+}
+
+// WithIssuer requires that a JWT's iss claim equal the given value.
+// By default, the issuer is not checked.
+func WithIssuer(iss string) VerifierOption {
+	return verifierOptionFunc(func(v *Verifier) error {
+		v.issuer = iss
+		return nil
+	})
+}
+
+// WithAudience requires that a JWT's aud claim contain the given value.
+// By default, the audience is not checked.
+func WithAudience(aud string) VerifierOption {
+	return verifierOptionFunc(func(v *Verifier) error {
+		v.audience = aud
+		return nil
+	})
+}
+
+// WithLeeway sets the allowed clock skew when checking a JWT's exp, nbf, and
+// iat claims.  By default, there is no leeway.
+func WithLeeway(d time.Duration) VerifierOption {
+	return verifierOptionFunc(func(v *Verifier) error {
+		v.leeway = d
+		return nil
+	})
+}
+
+// Verifier verifies JWS messages and JWTs.  A Verifier is safe for concurrent use.
+type Verifier struct {
+	keys       clortho.KeyAccessor
+	resolver   clortho.Resolver
+	algorithms map[string]bool
+	issuer     string
+	audience   string
+	leeway     time.Duration
+	clock      chronon.Clock
+}
+
+// NewVerifier constructs a *Verifier from a set of options.  At least one of
+// WithKeyAccessor or WithResolver is required.
+func NewVerifier(options ...VerifierOption) (v *Verifier, err error) {
+	v = &Verifier{
+		clock: chronon.SystemClock(),
+	}
+
+	WithAlgorithms(DefaultAlgorithms()...).applyToVerifier(v)
+	for _, o := range options {
+		err = multierr.Append(err, o.applyToVerifier(v))
+	}
+
+	if v.keys == nil && v.resolver == nil {
+		err = multierr.Append(err, ErrNoKeys)
+	}
+
+	if err != nil {
+		v = nil
+	}
+
+	return
+}
+
+// KeyHint holds the JOSE headers that identify the key for a signature.
+type KeyHint struct {
+	// KeyID is the kid header.
+	KeyID string
+
+	// Algorithm is the alg header.
+	Algorithm string
+
+	// Thumbprint is the x5t header, the base64url-encoded SHA-1 thumbprint of
+	// the key's certificate.
+	Thumbprint string
+
+	// ThumbprintS256 is the x5t#S256 header, the base64url-encoded SHA-256 thumbprint
+	// of the key's certificate.
+	ThumbprintS256 string
+}
+
+// hasThumbprint tests if this hint has any certificate thumbprint.
+func (kh KeyHint) hasThumbprint() bool {
+	return len(kh.ThumbprintS256) > 0 || len(kh.Thumbprint) > 0
+}
+
+// matchesThumbprints tests if a key's certificate matches every thumbprint in this hint.
+func (kh KeyHint) matchesThumbprints(k clortho.Key) bool {
+	return (len(kh.ThumbprintS256) == 0 || k.CertificateThumbprintS256() == kh.ThumbprintS256) &&
+		(len(kh.Thumbprint) == 0 || k.CertificateThumbprint() == kh.Thumbprint)
+}
+
+// checkThumbprints verifies that any thumbprints in this hint match the key's certificate.
+// Keys without certificate thumbprints aren't checked.
+func (kh KeyHint) checkThumbprints(k clortho.Key) error {
+	if t := k.CertificateThumbprintS256(); len(kh.ThumbprintS256) > 0 && len(t) > 0 && kh.ThumbprintS256 != t {
+		return fmt.Errorf("%w: x5t#S256 does not match the key's certificate", ErrKeyMismatch)
+	}
+
+	if t := k.CertificateThumbprint(); len(kh.Thumbprint) > 0 && len(t) > 0 && kh.Thumbprint != t {
+		return fmt.Errorf("%w: x5t does not match the key's certificate", ErrKeyMismatch)
+	}
+
+	return nil
+}
+
+// Key looks up the key for a kid and checks it against a signature algorithm.
+// This is equivalent to KeyFor with a KeyHint containing only the kid and alg.
+func (v *Verifier) Key(ctx context.Context, keyID, alg string) (clortho.Key, error) {
+	return v.KeyFor(ctx, KeyHint{
+		KeyID:     keyID,
+		Algorithm: alg,
+	})
+}
+
+// KeyFor looks up the key identified by a set of headers and checks it against the
+// signature algorithm.  The algorithm must be in this Verifier's allowlist, and the
+// key must pass CheckKey.  Any certificate thumbprints in the hint must match the key.
+//
+// A kid is looked up in the KeyAccessor first, followed by the Resolver.  The context is
+// passed to Resolver.Resolve.  If neither has the key, an *UnknownKeyIDError is returned.
+//
+// If there is no kid, the key is the one in the KeyAccessor whose certificate matches every
+// thumbprint in the hint.  See clortho.KeyAccessor.GetByCertificateThumbprint.  Thumbprints are never passed to the Resolver, since
+// they aren't key IDs.  If no key matches, an error wrapping ErrUnknownThumbprint is returned.
+func (v *Verifier) KeyFor(ctx context.Context, hint KeyHint) (clortho.Key, error) {
+	if !v.algorithms[hint.Algorithm] {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithmNotAllowed, hint.Algorithm)
+	}
+
+	var (
+		k   clortho.Key
+		err error
+	)
+
+	switch {
+	case len(hint.KeyID) > 0:
+		k, err = v.keyByID(ctx, hint.KeyID)
+
+	case hint.hasThumbprint():
+		k, err = v.keyByThumbprint(hint)
+
+	default:
+		err = ErrMissingKeyID
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = CheckKey(k, hint.Algorithm); err != nil {
+		return nil, err
+	}
+
+	if err = hint.checkThumbprints(k); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// keyByID looks up a kid in the KeyAccessor, then the Resolver.
+func (v *Verifier) keyByID(ctx context.Context, keyID string) (k clortho.Key, err error) {
+	var ok bool
+	if v.keys != nil {
+		k, ok = v.keys.Get(keyID)
+	}
+
+	if !ok && v.resolver != nil {
+		k, err = v.resolver.Resolve(ctx, keyID)
+		ok = err == nil
+	}
+
+	if !ok {
+		return nil, &UnknownKeyIDError{
+			KeyID: keyID,
+			Err:   err,
+		}
+	}
+
+	return k, nil
+}
+
+// keyByThumbprint looks up the key whose certificate matches the hint's thumbprints
+// in the KeyAccessor.  The SHA-256 thumbprint is preferred for the lookup.
+func (v *Verifier) keyByThumbprint(hint KeyHint) (k clortho.Key, err error) {
+	h, t := crypto.SHA256, hint.ThumbprintS256
+	if len(t) == 0 {
+		h, t = crypto.SHA1, hint.Thumbprint
+	}
+
+	var ok bool
+	if v.keys != nil {
+		k, ok = v.keys.GetByCertificateThumbprint(h, t)
+	}
+
+	if !ok || !hint.matchesThumbprints(k) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownThumbprint, t)
+	}
+
+	return k, nil
+}
+
+// hintFromJWS extracts a KeyHint from JWS protected headers.
+func hintFromJWS(headers jws.Headers) KeyHint {
+	return KeyHint{
+		KeyID:          headers.KeyID(),
+		Algorithm:      headers.Algorithm().String(),
+		Thumbprint:     headers.X509CertThumbprint(),
+		ThumbprintS256: headers.X509CertThumbprintS256(),
+	}
+}
+
+// VerifyJWS verifies a compact JWS.  The message must have exactly one signature
+// whose protected headers identify the key.  See KeyFor.  The verified payload is returned along
+// with the key that verified it.
+func (v *Verifier) VerifyJWS(ctx context.Context, token []byte) ([]byte, clortho.Key, error) {
+	msg, err := jws.Parse(token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	} else if len(msg.Signatures()) != 1 {
+		return nil, nil, fmt.Errorf("%w: expected exactly one signature", ErrMalformed)
+	}
+
+	var (
+		headers = msg.Signatures()[0].ProtectedHeaders()
+		alg     = headers.Algorithm()
+	)
+
+	k, err := v.KeyFor(ctx, hintFromJWS(headers))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload, err := jws.Verify(token, jws.WithKey(alg, k.Public()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	return payload, k, nil
+}
+
+// VerifyJWT verifies a compact JWS, as with VerifyJWS, and then validates its payload
+// as a set of JWT claims.  The exp, nbf, and iat claims are checked when present, as are
+// iss and aud when WithIssuer or WithAudience were used.
+func (v *Verifier) VerifyJWT(ctx context.Context, token []byte) (jwt.Token, clortho.Key, error) {
+	payload, k, err := v.VerifyJWS(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := jwt.New()
+	if err := json.Unmarshal(payload, t); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	options := []jwt.ValidateOption{
+		jwt.WithClock(jwt.ClockFunc(v.clock.Now)),
+		jwt.WithAcceptableSkew(v.leeway),
+	}
+
+	if len(v.issuer) > 0 {
+		options = append(options, jwt.WithIssuer(v.issuer))
+	}
+
+	if len(v.audience) > 0 {
+		options = append(options, jwt.WithAudience(v.audience))
+	}
+
+	if err := jwt.Validate(t, options...); err != nil {
+		return nil, nil, translateValidationError(err)
+	}
+
+	return t, k, nil
+}
+
+// translateValidationError maps a jwt validation error onto this package's errors.
+func translateValidationError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired()):
+		return fmt.Errorf("%w: %w", ErrTokenExpired, err)
+
+	case errors.Is(err, jwt.ErrTokenNotYetValid()):
+		return fmt.Errorf("%w: %w", ErrTokenNotYetValid, err)
+
+	case errors.Is(err, jwt.ErrInvalidIssuer()):
+		return fmt.Errorf("%w: %w", ErrInvalidIssuer, err)
+
+	case errors.Is(err, jwt.ErrInvalidAudience()):
+		return fmt.Errorf("%w: %w", ErrInvalidAudience, err)
+
+	default:
+		return fmt.Errorf("%w: %w", ErrInvalidClaims, err)
+	}
+}
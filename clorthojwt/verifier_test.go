@@ -0,0 +1,306 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clorthojwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/clortho"
+)
+
+// stubResolver is a clortho.Resolver backed by a fixed set of keys.
+type stubResolver struct {
+	clortho.Resolver
+	keys map[string]clortho.Key
+}
+
+func (sr stubResolver) Resolve(_ context.Context, keyID string) (clortho.Key, error) {
+	if k, ok := sr.keys[keyID]; ok {
+		return k, nil
+	}
+
+	return nil, clortho.ErrKeyNotFound
+}
+
+type VerifierSuite struct {
+	suite.Suite
+
+	privateKey jwk.Key
+	publicKey  clortho.Key
+	keyRing    clortho.KeyRing
+}
+
+// newKeyPair generates an EC key pair.  The private key is returned as a jwk.Key for
+// signing, and the public key is parsed into a clortho.Key.  The extra fields are set
+// on the public key prior to parsing.
+func (suite *VerifierSuite) newKeyPair(keyID string, extra map[string]interface{}) (jwk.Key, clortho.Key) {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+
+	private, err := jwk.FromRaw(pk)
+	suite.Require().NoError(err)
+	suite.Require().NoError(private.Set(jwk.KeyIDKey, keyID))
+
+	public, err := jwk.FromRaw(pk.Public())
+	suite.Require().NoError(err)
+	suite.Require().NoError(public.Set(jwk.KeyIDKey, keyID))
+	for name, value := range extra {
+		suite.Require().NoError(public.Set(name, value))
+	}
+
+	data, err := json.Marshal(public)
+	suite.Require().NoError(err)
+
+	p, err := clortho.NewParser()
+	suite.Require().NoError(err)
+
+	keys, err := p.Parse(clortho.MediaTypeJWK, data)
+	suite.Require().NoError(err)
+	suite.Require().Len(keys, 1)
+
+	return private, keys[0]
+}
+
+func (suite *VerifierSuite) SetupTest() {
+	suite.privateKey, suite.publicKey = suite.newKeyPair("test", nil)
+	suite.keyRing = clortho.NewKeyRing(suite.publicKey)
+}
+
+func (suite *VerifierSuite) newVerifier(options ...VerifierOption) *Verifier {
+	v, err := NewVerifier(options...)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(v)
+	return v
+}
+
+// sign creates a signed JWT with the given claims.
+func (suite *VerifierSuite) sign(signer jwk.Key, alg jwa.SignatureAlgorithm, claims map[string]interface{}) []byte {
+	t := jwt.New()
+	for name, value := range claims {
+		suite.Require().NoError(t.Set(name, value))
+	}
+
+	signed, err := jwt.Sign(t, jwt.WithKey(alg, signer))
+	suite.Require().NoError(err)
+	return signed
+}
+
+func (suite *VerifierSuite) TestNoKeys() {
+	v, err := NewVerifier()
+	suite.Nil(v)
+	suite.ErrorIs(err, ErrNoKeys)
+}
+
+func (suite *VerifierSuite) TestUnsupportedAlgorithm() {
+	v, err := NewVerifier(
+		WithKeyAccessor(suite.keyRing),
+		WithAlgorithms("none"),
+	)
+
+	suite.Nil(v)
+	suite.Error(err)
+}
+
+func (suite *VerifierSuite) TestValid() {
+	var (
+		v = suite.newVerifier(
+			WithKeyAccessor(suite.keyRing),
+			WithIssuer("issuer"),
+			WithAudience("audience"),
+		)
+
+		token = suite.sign(suite.privateKey, jwa.ES256, map[string]interface{}{
+			jwt.IssuerKey:     "issuer",
+			jwt.AudienceKey:   []string{"audience"},
+			jwt.ExpirationKey: time.Now().Add(time.Hour),
+			jwt.SubjectKey:    "subject",
+		})
+	)
+
+	t, k, err := v.VerifyJWT(context.Background(), token)
+	suite.Require().NoError(err)
+	suite.Equal(suite.publicKey, k)
+	suite.Equal("subject", t.Subject())
+
+	payload, k, err := v.VerifyJWS(context.Background(), token)
+	suite.Require().NoError(err)
+	suite.NotEmpty(payload)
+	suite.Equal(suite.publicKey, k)
+}
+
+func (suite *VerifierSuite) TestResolver() {
+	var (
+		private, public = suite.newKeyPair("resolved", nil)
+
+		v = suite.newVerifier(
+			WithKeyAccessor(suite.keyRing),
+			WithResolver(stubResolver{
+				keys: map[string]clortho.Key{"resolved": public},
+			}),
+		)
+	)
+
+	_, k, err := v.VerifyJWT(context.Background(), suite.sign(private, jwa.ES256, nil))
+	suite.Require().NoError(err)
+	suite.Equal(public, k)
+}
+
+func (suite *VerifierSuite) TestUnknownKeyID() {
+	var (
+		private, _ = suite.newKeyPair("unknown", nil)
+
+		v = suite.newVerifier(
+			WithKeyAccessor(suite.keyRing),
+			WithResolver(stubResolver{}),
+		)
+
+		ukie *UnknownKeyIDError
+	)
+
+	_, _, err := v.VerifyJWT(context.Background(), suite.sign(private, jwa.ES256, nil))
+	suite.Require().ErrorAs(err, &ukie)
+	suite.Equal("unknown", ukie.KeyID)
+	suite.ErrorIs(err, clortho.ErrKeyNotFound)
+}
+
+func (suite *VerifierSuite) TestMissingKeyID() {
+	var (
+		private, _ = suite.newKeyPair("", nil)
+		v          = suite.newVerifier(WithKeyAccessor(suite.keyRing))
+	)
+
+	_, _, err := v.VerifyJWT(context.Background(), suite.sign(private, jwa.ES256, nil))
+	suite.ErrorIs(err, ErrMissingKeyID)
+}
+
+func (suite *VerifierSuite) TestInvalidSignature() {
+	var (
+		// same kid, different key material
+		private, _ = suite.newKeyPair("test", nil)
+		v          = suite.newVerifier(WithKeyAccessor(suite.keyRing))
+	)
+
+	_, _, err := v.VerifyJWT(context.Background(), suite.sign(private, jwa.ES256, nil))
+	suite.ErrorIs(err, ErrInvalidSignature)
+}
+
+func (suite *VerifierSuite) TestMalformed() {
+	v := suite.newVerifier(WithKeyAccessor(suite.keyRing))
+	_, _, err := v.VerifyJWT(context.Background(), []byte("this is not a token"))
+	suite.ErrorIs(err, ErrMalformed)
+}
+
+func (suite *VerifierSuite) TestAlgorithmNotAllowed() {
+	v := suite.newVerifier(
+		WithKeyAccessor(suite.keyRing),
+		WithAlgorithms(jwa.RS256.String()),
+	)
+
+	_, _, err := v.VerifyJWT(context.Background(), suite.sign(suite.privateKey, jwa.ES256, nil))
+	suite.ErrorIs(err, ErrAlgorithmNotAllowed)
+}
+
+func (suite *VerifierSuite) TestKeyMismatch() {
+	suite.Run("Algorithm", func() {
+		var (
+			private, public = suite.newKeyPair("declared", map[string]interface{}{
+				jwk.AlgorithmKey: jwa.ES384,
+			})
+
+			v = suite.newVerifier(WithKeyAccessor(clortho.NewKeyRing(public)))
+		)
+
+		_, _, err := v.VerifyJWT(context.Background(), suite.sign(private, jwa.ES256, nil))
+		suite.ErrorIs(err, ErrKeyMismatch)
+	})
+
+	suite.Run("Usage", func() {
+		var (
+			private, public = suite.newKeyPair("encryption", map[string]interface{}{
+				jwk.KeyUsageKey: "enc",
+			})
+
+			v = suite.newVerifier(WithKeyAccessor(clortho.NewKeyRing(public)))
+		)
+
+		_, _, err := v.VerifyJWT(context.Background(), suite.sign(private, jwa.ES256, nil))
+		suite.ErrorIs(err, ErrKeyMismatch)
+	})
+
+	suite.Run("KeyType", func() {
+		suite.ErrorIs(CheckKey(suite.publicKey, jwa.RS256.String()), ErrKeyMismatch)
+		suite.NoError(CheckKey(suite.publicKey, jwa.ES256.String()))
+	})
+}
+
+func (suite *VerifierSuite) TestClaims() {
+	v := suite.newVerifier(
+		WithKeyAccessor(suite.keyRing),
+		WithIssuer("issuer"),
+		WithAudience("audience"),
+		WithLeeway(time.Minute),
+	)
+
+	suite.Run("Expired", func() {
+		_, _, err := v.VerifyJWT(context.Background(), suite.sign(suite.privateKey, jwa.ES256, map[string]interface{}{
+			jwt.IssuerKey:     "issuer",
+			jwt.AudienceKey:   []string{"audience"},
+			jwt.ExpirationKey: time.Now().Add(-time.Hour),
+		}))
+
+		suite.ErrorIs(err, ErrTokenExpired)
+	})
+
+	suite.Run("ExpiredWithinLeeway", func() {
+		_, _, err := v.VerifyJWT(context.Background(), suite.sign(suite.privateKey, jwa.ES256, map[string]interface{}{
+			jwt.IssuerKey:     "issuer",
+			jwt.AudienceKey:   []string{"audience"},
+			jwt.ExpirationKey: time.Now().Add(-10 * time.Second),
+		}))
+
+		suite.NoError(err)
+	})
+
+	suite.Run("NotYetValid", func() {
+		_, _, err := v.VerifyJWT(context.Background(), suite.sign(suite.privateKey, jwa.ES256, map[string]interface{}{
+			jwt.IssuerKey:    "issuer",
+			jwt.AudienceKey:  []string{"audience"},
+			jwt.NotBeforeKey: time.Now().Add(time.Hour),
+		}))
+
+		suite.ErrorIs(err, ErrTokenNotYetValid)
+	})
+
+	suite.Run("Issuer", func() {
+		_, _, err := v.VerifyJWT(context.Background(), suite.sign(suite.privateKey, jwa.ES256, map[string]interface{}{
+			jwt.IssuerKey:   "someone else",
+			jwt.AudienceKey: []string{"audience"},
+		}))
+
+		suite.ErrorIs(err, ErrInvalidIssuer)
+	})
+
+	suite.Run("Audience", func() {
+		_, _, err := v.VerifyJWT(context.Background(), suite.sign(suite.privateKey, jwa.ES256, map[string]interface{}{
+			jwt.IssuerKey:   "issuer",
+			jwt.AudienceKey: []string{"someone else"},
+		}))
+
+		suite.ErrorIs(err, ErrInvalidAudience)
+	})
+}
+
+func TestVerifier(t *testing.T) {
+	suite.Run(t, new(VerifierSuite))
+}
@@ -4,6 +4,8 @@
 package clorthometrics
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/xmidt-org/clortho"
 	"github.com/xmidt-org/touchstone"
@@ -41,6 +43,9 @@ func WithFactory(f *touchstone.Factory) ListenerOption {
 		l.resolveErrorTotal, metricErr = newResolveErrorTotal(f)
 		err = multierr.Append(err, metricErr)
 
+		l.resolveRateLimitedTotal, metricErr = newResolveRateLimitedTotal(f)
+		err = multierr.Append(err, metricErr)
+
 		return
 	})
 }
@@ -51,8 +56,9 @@ type Listener struct {
 	refreshKeys       *prometheus.GaugeVec
 	refreshErrorTotal *prometheus.CounterVec
 
-	resolveTotal      *prometheus.CounterVec
-	resolveErrorTotal *prometheus.CounterVec
+	resolveTotal            *prometheus.CounterVec
+	resolveErrorTotal       *prometheus.CounterVec
+	resolveRateLimitedTotal prometheus.Counter
 }
 
 var _ clortho.RefreshListener = (*Listener)(nil)
@@ -88,8 +94,14 @@ func (l *Listener) OnRefreshEvent(event clortho.RefreshEvent) {
 	}
 }
 
-// OnResolveEvent tallies metrics for the given ResolveEvent.
+// OnResolveEvent tallies metrics for the given ResolveEvent.  Events for keys that
+// were already cached are ignored, since no fetch was attempted.
 func (l *Listener) OnResolveEvent(event clortho.ResolveEvent) {
+	if event.Cached {
+		// no fetch was attempted
+		return
+	}
+
 	labels := prometheus.Labels{
 		SourceLabel: event.URI,
 		KeyIDLabel:  event.KeyID,
@@ -100,4 +112,8 @@ func (l *Listener) OnResolveEvent(event clortho.ResolveEvent) {
 	if event.Err != nil {
 		l.resolveErrorTotal.With(labels).Add(1.0)
 	}
+
+	if errors.Is(event.Err, clortho.ErrResolveRateLimited) {
+		l.resolveRateLimitedTotal.Add(1.0)
+	}
 }
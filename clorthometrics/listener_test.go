@@ -4,7 +4,9 @@
 package clorthometrics
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -205,9 +207,68 @@ func (suite *ListenerSuite) testOnResolveEventError() {
 	assert.GatherAndCompare(actual)
 }
 
+func (suite *ListenerSuite) testOnResolveEventRateLimited() {
+	var (
+		actual, actualFactory = suite.newFactory()
+		actualListener        = suite.newListener(actualFactory)
+
+		expected, expectedFactory = suite.newFactory()
+		expectedListener          = suite.newListener(expectedFactory)
+		expectedLabels            = prometheus.Labels{
+			SourceLabel: "",
+			KeyIDLabel:  "test",
+		}
+
+		assert = touchtest.New(suite.T())
+	)
+
+	expectedListener.resolveTotal.With(expectedLabels).Add(1.0)
+	expectedListener.resolveErrorTotal.With(expectedLabels).Add(1.0)
+	expectedListener.resolveRateLimitedTotal.Add(1.0)
+	assert.Expect(expected)
+
+	actualListener.OnResolveEvent(clortho.ResolveEvent{
+		KeyID: "test",
+		Err:   fmt.Errorf("%w: expected", clortho.ErrResolveRateLimited),
+	})
+
+	assert.GatherAndCompare(actual)
+}
+
 func (suite *ListenerSuite) TestOnResolveEvent() {
 	suite.Run("Success", suite.testOnResolveEventSuccess)
 	suite.Run("Error", suite.testOnResolveEventError)
+	suite.Run("RateLimited", suite.testOnResolveEventRateLimited)
+}
+
+func (suite *ListenerSuite) TestResolveAll() {
+	var (
+		actual, actualFactory = suite.newFactory()
+		actualListener        = suite.newListener(actualFactory)
+
+		expected, expectedFactory = suite.newFactory()
+		_                         = suite.newListener(expectedFactory)
+
+		assert = touchtest.New(suite.T())
+	)
+
+	r, err := clortho.NewResolver(
+		clortho.WithKeyIDTemplate("https://getkeys.com/{keyID}"),
+		clortho.WithKeyIDValidators(clortho.KeyIDMaxLength(10)),
+		clortho.WithKeyRing(clortho.NewKeyRing(suite.keys[0])),
+	)
+
+	suite.Require().NoError(err)
+	r.AddListener(actualListener)
+
+	// neither the cached key nor the invalid key ID is fetched, so nothing is tallied
+	results, err := r.ResolveAll(context.Background(), suite.keys[0].KeyID(), "thisKeyIDIsTooLong")
+	suite.ErrorIs(err, clortho.ErrKeyIDTooLong)
+	suite.Require().Len(results, 2)
+	suite.NoError(results[0].Err)
+
+	assert.Expect(expected)
+	assert.GatherAndCompare(actual)
 }
 
 func TestListener(t *testing.T) {
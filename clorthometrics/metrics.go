@@ -48,6 +48,13 @@ const (
 	// ResolveErrorTotalHelp is the help text for the resolve error metric.
 	ResolveErrorTotalHelp = "the total failed attempts to resolve individual keys"
 
+	// ResolveRateLimitedTotalName is the name of the counter for resolve attempts
+	// that were rejected by a Resolver's concurrency cap or rate limit.
+	ResolveRateLimitedTotalName = MetricPrefix + "resolve_rate_limited_total"
+
+	// ResolveRateLimitedTotalHelp is the help text for the resolve rate limited metric.
+	ResolveRateLimitedTotalHelp = "the total attempts to resolve individual keys that were rejected by a concurrency cap or rate limit"
+
 	// SourceLabel is the metric label indicating the URI source of the key(s).
 	SourceLabel = "source"
 
@@ -106,3 +113,12 @@ func newResolveErrorTotal(f *touchstone.Factory) (m *prometheus.CounterVec, err
 		KeyIDLabel,
 	)
 }
+
+func newResolveRateLimitedTotal(f *touchstone.Factory) (m prometheus.Counter, err error) {
+	return f.NewCounter(
+		prometheus.CounterOpts{
+			Name: ResolveRateLimitedTotalName,
+			Help: ResolveRateLimitedTotalHelp,
+		},
+	)
+}
@@ -103,8 +103,14 @@ func (l *Listener) OnRefreshEvent(event clortho.RefreshEvent) {
 }
 
 // OnResolveEvent outputs structured logging about the event to the logger
-// established via WithLogger when this listener was created.
+// established via WithLogger when this listener was created.  Events for keys
+// that were already cached are not logged, since no fetch was attempted.
 func (l *Listener) OnResolveEvent(event clortho.ResolveEvent) {
+	if event.Cached {
+		// no fetch was attempted
+		return
+	}
+
 	level := zapcore.InfoLevel
 	if event.Err != nil {
 		level = zapcore.ErrorLevel
@@ -118,6 +124,7 @@ func (l *Listener) OnResolveEvent(event clortho.ResolveEvent) {
 	ce.Write(
 		zap.String("uri", event.URI),
 		zap.String("keyID", event.KeyID),
+		zap.Bool("cachedMiss", event.CachedMiss),
 		zap.Error(event.Err),
 	)
 }
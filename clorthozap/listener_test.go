@@ -151,6 +151,7 @@ func (suite *ListenerSuite) assertResolveEntry(b *bytes.Buffer, expectedEvent cl
 
 	suite.Equal(expectedEvent.URI, m["uri"])
 	suite.Equal(expectedEvent.KeyID, m["keyID"])
+	suite.Equal(expectedEvent.CachedMiss, m["cachedMiss"])
 	suite.Equal(expectedLevel.String(), m["level"])
 
 	if expectedEvent.Err != nil {
@@ -323,6 +324,11 @@ func (suite *ListenerSuite) testOnResolveEventNoError() {
 	suite.Empty(output.Bytes())
 	listener.OnResolveEvent(event)
 	suite.assertResolveEntry(output, event, zapcore.InfoLevel)
+
+	output.Reset()
+	event.Cached = true
+	listener.OnResolveEvent(event)
+	suite.Empty(output.Bytes())
 }
 
 func (suite *ListenerSuite) testOnResolveEventError() {
@@ -336,7 +342,8 @@ func (suite *ListenerSuite) testOnResolveEventError() {
 			URI:   "https://getkeys.com/foo",
 			KeyID: "foo",
 			// NOTE: we don't use the Key field for logging
-			Err: expectedError,
+			Err:        expectedError,
+			CachedMiss: true,
 		}
 	)
 
@@ -22,8 +22,55 @@ const (
 
 	// DefaultRefreshJitter is the default randomization factor for key refreshes.
 	DefaultRefreshJitter = 0.1
+
+	// DefaultRefreshBackoffInitialDelay is the default delay before the first retry of a
+	// refresh source that has failed.
+	DefaultRefreshBackoffInitialDelay = time.Second * 10
+
+	// DefaultRefreshBackoffMultiplier is the default factor by which the retry delay grows
+	// with each consecutive failure.
+	DefaultRefreshBackoffMultiplier = 2.0
+
+	// DefaultRefreshBackoffMaxDelay is the default upper bound on the retry delay.
+	DefaultRefreshBackoffMaxDelay = time.Hour
+
+	// DefaultResolveBatchWorkers is the default number of key IDs that Resolver.ResolveAll
+	// resolves concurrently.
+	DefaultResolveBatchWorkers = 8
+
+	// DefaultResolveNegativeCacheSize is the default upper bound on the number of unknown
+	// key IDs a Resolver remembers.
+	DefaultResolveNegativeCacheSize = 1000
 )
 
+// RefreshBackoff describes how a refresh source is retried after consecutive errors.
+// The delay before the nth consecutive retry is InitialDelay * Multiplier^(n-1), with the
+// source's jitter applied, capped at MaxDelay.  A retry never waits longer than the source
+// would have without a backoff policy.  Any Retry-After sent by an HTTP server with a
+// 429 or 503 response is honored, up to MaxDelay.  Once MaxRetries is exceeded, Retry-After
+// is honored up to the source's normal interval.
+//
+// Retry delays are not subject to the source's MinInterval.  Once a refresh succeeds,
+// the source reverts to its normal interval.
+type RefreshBackoff struct {
+	// InitialDelay is the delay before the first retry.  If this value is not positive,
+	// DefaultRefreshBackoffInitialDelay is used.
+	InitialDelay time.Duration `json:"initialDelay" yaml:"initialDelay"`
+
+	// Multiplier is the factor by which the delay grows with each consecutive error.
+	// If this value is less than 1.0, DefaultRefreshBackoffMultiplier is used.
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+
+	// MaxDelay is the upper bound on the delay between retries.  If this value is not
+	// positive, DefaultRefreshBackoffMaxDelay is used.
+	MaxDelay time.Duration `json:"maxDelay" yaml:"maxDelay"`
+
+	// MaxRetries is the optional cap on the number of consecutive retries that use this
+	// backoff policy.  Once exceeded, the source is refreshed on its normal schedule until
+	// a refresh succeeds.  If this value is not positive, there is no cap.
+	MaxRetries int `json:"maxRetries" yaml:"maxRetries"`
+}
+
 // RefreshSource describes a single location where keys are retrieved on a schedule.
 type RefreshSource struct {
 	// URI is the location where keys are served.  By default, clortho supports
@@ -42,7 +89,8 @@ type RefreshSource struct {
 
 	// MinInterval specifies the absolute minimum time between key refreshes from this source.
 	// Regardless of HTTP headers, the Interval field, etc, key refreshes will not occur more
-	// often than this field indicates.
+	// often than this field indicates.  The only exception is retries after errors, which are
+	// governed by the Backoff field.
 	//
 	// If this value is not positive, DefaultRefreshMinInterval is used.
 	MinInterval time.Duration `json:"minInterval" yaml:"minInterval"`
@@ -54,6 +102,15 @@ type RefreshSource struct {
 	// Valid values are between 0.0 and 1.0, exclusive.  If this value is outside that range,
 	// including being unset, DefaultRefreshJitter is used instead.
 	Jitter float64 `json:"jitter" yaml:"jitter"`
+
+	// Backoff is the policy for retrying this source after consecutive errors.  The zero value
+	// uses all the defaults.
+	Backoff RefreshBackoff `json:"backoff" yaml:"backoff"`
+
+	// Required indicates that this source must produce keys before a Refresher is considered
+	// started.  If any source is required, Refresher.Start blocks until each required source
+	// has been successfully fetched at least once or until the Start context expires.
+	Required bool `json:"required" yaml:"required"`
 }
 
 // validate checks that this RefreshSource is valid.
@@ -82,15 +139,79 @@ func validateRefreshSources(in ...RefreshSource) (err error) {
 	return
 }
 
+// ResolveTemplate is a fallback URI template used to fetch keys.
+type ResolveTemplate struct {
+	// Template is a URI template used to fetch keys.  This template may
+	// use a single parameter named keyID, e.g. http://legacy.keys.com/{keyID}.
+	Template string `json:"template" yaml:"template"`
+
+	// Timeout is the maximum time to wait for each fetch using this template.  If unset,
+	// the ResolveConfig.Timeout is used.
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
 // ResolveConfig configures how to fetch individual keys on demand.
 type ResolveConfig struct {
 	// Template is a URI template used to fetch keys.  This template may
 	// use a single parameter named keyID, e.g. http://keys.com/{keyID}.
 	Template string `json:"template" yaml:"template"`
 
-	// Timeout refers to the maximum time to wait for a refresh operation.
+	// RefreshOnMiss indicates that the refresh sources should be refreshed when a key is
+	// not found, before any URI template is tried.  When this field is set, Template is
+	// optional.  A Config alone can't supply the Refresher, so this field is only honored
+	// where one is available, e.g. by clorthofx.  See WithRefreshOnMiss.
+	RefreshOnMiss bool `json:"refreshOnMiss" yaml:"refreshOnMiss"`
+
+	// Fallbacks are URI templates tried, in order, when the primary Template fails to
+	// produce a key because of a missing key, a 404 response, a timeout, or a network error.
+	Fallbacks []ResolveTemplate `json:"fallbacks" yaml:"fallbacks"`
+
+	// KeyIDMaxLength is the maximum length, in bytes, of a key ID that will be resolved.
+	// If this value is not positive, key IDs of any length are allowed.  See KeyIDMaxLength.
+	KeyIDMaxLength int `json:"keyIDMaxLength" yaml:"keyIDMaxLength"`
+
+	// KeyIDPattern is a regular expression that each key ID must match in order to be
+	// resolved, e.g. ^[A-Za-z0-9_-]+$.  If unset, no pattern is enforced.  See KeyIDMatches.
+	KeyIDPattern string `json:"keyIDPattern" yaml:"keyIDPattern"`
+
+	// Timeout is the maximum time to wait for each fetch of a key.  A fetch that
+	// exceeds this timeout fails with a *ResolveTimeoutError.  See WithResolveTimeout.
+	//
 	// There is no default for this field.  If unset, no timeout is applied.
 	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+
+	// NegativeCacheTTL is how long a Resolver remembers that a key ID could not be found,
+	// either because the fetched content did not contain the key or because the server
+	// responded with a 404.  A 404 response with Cache-Control or Expires headers uses
+	// the lifetime from those headers instead.
+	//
+	// If this value is not positive, unknown key IDs are not remembered.
+	NegativeCacheTTL time.Duration `json:"negativeCacheTTL" yaml:"negativeCacheTTL"`
+
+	// NegativeCacheSize is the maximum number of unknown key IDs a Resolver remembers.
+	// If this value is not positive, DefaultResolveNegativeCacheSize is used.
+	NegativeCacheSize int `json:"negativeCacheSize" yaml:"negativeCacheSize"`
+
+	// BatchWorkers is the number of key IDs resolved concurrently by Resolver.ResolveAll.
+	// If this value is not positive, DefaultResolveBatchWorkers is used.
+	BatchWorkers int `json:"batchWorkers" yaml:"batchWorkers"`
+
+	// MaxConcurrency is the maximum number of keys fetched at the same time.  If this
+	// value is not positive, there is no limit.  See WithResolveConcurrency.
+	MaxConcurrency int `json:"maxConcurrency" yaml:"maxConcurrency"`
+
+	// RateLimit is the maximum number of fetches per second, averaged over time.
+	// If this value is not positive, there is no limit.  See WithResolveRateLimit.
+	RateLimit float64 `json:"rateLimit" yaml:"rateLimit"`
+
+	// RateBurst is the maximum number of fetches allowed at once by the RateLimit.
+	// If this value is less than 1, a burst of 1 is used.
+	RateBurst int `json:"rateBurst" yaml:"rateBurst"`
+
+	// MaxQueueWait is the maximum time a fetch waits on the MaxConcurrency and RateLimit
+	// limits before failing with ErrResolveRateLimited.  If this value is not positive,
+	// fetches that cannot proceed immediately fail.  See WithResolveQueueWait.
+	MaxQueueWait time.Duration `json:"maxQueueWait" yaml:"maxQueueWait"`
 }
 
 // RefreshConfig configures all aspects of key refresh.
@@ -102,6 +223,10 @@ type RefreshConfig struct {
 	//
 	// If there are multiple sources with the same URI, an error is raised.
 	Sources []RefreshSource `json:"sources" yaml:"sources"`
+
+	// RequireAllSources treats every source as required, regardless of each source's
+	// Required field.  See RefreshSource.Required.
+	RequireAllSources bool `json:"requireAllSources" yaml:"requireAllSources"`
 }
 
 // Config configures clortho from (possibly) externally unmarshaled locations.
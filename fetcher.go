@@ -19,6 +19,10 @@ type Fetcher interface {
 	//
 	// This method ensures that each key has a key ID.  For keys that do not have a key ID from their source,
 	// a key ID is generated using a thumbprint hash.
+	//
+	// If the content at location has not changed since prev was obtained, this method returns
+	// ErrNotModified along with the updated ContentMeta and no keys.  Callers should continue
+	// to use the keys from the previous call in that case.
 	Fetch(ctx context.Context, location string, prev ContentMeta) (keys []Key, next ContentMeta, err error)
 }
 
@@ -0,0 +1,248 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDeltaSeconds is the value used for any delta-seconds that overflows, as recommended
+// by RFC 9111 section 1.2.2.
+const maxDeltaSeconds = math.MaxInt32 + 1
+
+// cacheDirectives holds the subset of Cache-Control directives that affect how long
+// key material is considered fresh.
+type cacheDirectives struct {
+	maxAge    time.Duration
+	hasMaxAge bool
+
+	sMaxAge    time.Duration
+	hasSMaxAge bool
+
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+
+	noCache         bool
+	noStore         bool
+	private         bool
+	mustRevalidate  bool
+	proxyRevalidate bool
+}
+
+// parseDeltaSeconds parses a Cache-Control delta-seconds value.  Quoted values are
+// accepted, as RFC 9111 recommends.  The second return is false if the value is invalid.
+func parseDeltaSeconds(v string) (time.Duration, bool) {
+	v = strings.Trim(v, `"`)
+	seconds, err := strconv.ParseUint(v, 10, 64)
+	switch {
+	case errors.Is(err, strconv.ErrRange) || (err == nil && seconds > maxDeltaSeconds):
+		seconds = maxDeltaSeconds
+
+	case err != nil:
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// splitDirectives splits a Cache-Control value on commas, ignoring any commas
+// that appear within quoted strings.
+func splitDirectives(value string) (directives []string) {
+	var (
+		start  int
+		quoted bool
+	)
+
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			quoted = !quoted
+
+		case ',':
+			if !quoted {
+				directives = append(directives, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(directives, value[start:])
+}
+
+// parseCacheControl parses each Cache-Control header value.  Directive names are
+// case-insensitive.  Only the first occurrence of any directive is used, and invalid
+// directives are ignored.
+func parseCacheControl(values []string) (cd cacheDirectives) {
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, directive := range splitDirectives(value) {
+			name, arg, hasArg := strings.Cut(directive, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			arg = strings.TrimSpace(arg)
+			if len(name) == 0 || seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			switch name {
+			case "max-age":
+				cd.maxAge, cd.hasMaxAge = parseDeltaSeconds(arg)
+
+			case "s-maxage":
+				cd.sMaxAge, cd.hasSMaxAge = parseDeltaSeconds(arg)
+
+			case "stale-while-revalidate":
+				cd.staleWhileRevalidate, _ = parseDeltaSeconds(arg)
+
+			case "stale-if-error":
+				cd.staleIfError, _ = parseDeltaSeconds(arg)
+
+			case "no-cache":
+				// the qualified form only applies to the listed header fields
+				cd.noCache = !hasArg
+
+			case "no-store":
+				cd.noStore = true
+
+			case "private":
+				// the qualified form only applies to the listed header fields
+				cd.private = !hasArg
+
+			case "must-revalidate":
+				cd.mustRevalidate = true
+
+			case "proxy-revalidate":
+				cd.proxyRevalidate = true
+			}
+		}
+	}
+
+	return
+}
+
+// freshness is the caching information computed from an HTTP response.
+type freshness struct {
+	ttl                  time.Duration
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+	revalidate           bool
+}
+
+// applyTo copies this freshness information into a ContentMeta.
+func (f freshness) applyTo(meta *ContentMeta) {
+	meta.TTL = f.ttl
+	meta.StaleWhileRevalidate = f.staleWhileRevalidate
+	meta.StaleIfError = f.staleIfError
+	meta.Revalidate = f.revalidate
+}
+
+// parseHTTPTime parses the first value of a date header, e.g. Date or Expires.
+func parseHTTPTime(header http.Header, name string) (t time.Time, present, ok bool) {
+	if v := header.Get(name); len(v) > 0 {
+		var err error
+		t, err = http.ParseTime(v)
+		present, ok = true, err == nil
+	}
+
+	return
+}
+
+// currentAge computes the age of a response as of now, using the larger of the
+// Age header and the apparent age computed from the Date header.
+func currentAge(header http.Header, now time.Time) (age time.Duration) {
+	if v := header.Get("Age"); len(v) > 0 {
+		age, _ = parseDeltaSeconds(v)
+	}
+
+	if date, _, ok := parseHTTPTime(header, "Date"); ok {
+		if apparentAge := now.Sub(date); apparentAge > age {
+			age = apparentAge
+		}
+	}
+
+	return
+}
+
+// computeFreshness uses the freshness model of RFC 9111 to determine how long a response
+// remains fresh, as of now.  The shared flag indicates whether the calculation should be done
+// as a shared cache, which honors s-maxage, proxy-revalidate, and private.
+//
+// The second return is false if header contains no usable caching information, i.e. neither
+// a directive that forces revalidation nor an explicit freshness lifetime.
+func computeFreshness(header http.Header, now time.Time, shared bool) (f freshness, ok bool) {
+	cacheControl := header.Values("Cache-Control")
+	expires, hasExpires, validExpires := parseHTTPTime(header, "Expires")
+	if len(cacheControl) == 0 && !hasExpires {
+		return
+	}
+
+	ok = true
+	cd := parseCacheControl(cacheControl)
+	if cd.noCache || cd.noStore || (shared && cd.private) {
+		f.revalidate = true
+		return
+	}
+
+	var lifetime time.Duration
+	switch {
+	case shared && cd.hasSMaxAge:
+		lifetime = cd.sMaxAge
+
+	case cd.hasMaxAge:
+		lifetime = cd.maxAge
+
+	case hasExpires && validExpires:
+		date, _, validDate := parseHTTPTime(header, "Date")
+		if !validDate {
+			date = now
+		}
+
+		lifetime = expires.Sub(date)
+
+	case hasExpires:
+		// an invalid Expires, e.g. "0", represents a time in the past
+
+	default:
+		// no explicit freshness lifetime, so the caller's default interval applies
+		ok = false
+		return
+	}
+
+	f.ttl = lifetime - currentAge(header, now)
+	if f.ttl <= 0 {
+		f.ttl = 0
+		f.revalidate = true
+	}
+
+	// must-revalidate forbids using stale content, and so does proxy-revalidate or
+	// s-maxage for shared caches
+	if !cd.mustRevalidate && !(shared && (cd.proxyRevalidate || cd.hasSMaxAge)) {
+		f.staleWhileRevalidate = cd.staleWhileRevalidate
+		f.staleIfError = cd.staleIfError
+	}
+
+	return
+}
+
+// parseRetryAfter parses a Retry-After header, which may be either delta-seconds or an
+// HTTP date.  A missing, invalid, or past value results in a zero delay.
+func parseRetryAfter(header http.Header, now time.Time) (delay time.Duration) {
+	v := header.Get("Retry-After")
+	if len(v) == 0 {
+		return
+	}
+
+	if seconds, ok := parseDeltaSeconds(v); ok {
+		delay = seconds
+	} else if t, err := http.ParseTime(v); err == nil {
+		delay = max(t.Sub(now), 0)
+	}
+
+	return
+}
@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FreshnessSuite struct {
+	suite.Suite
+
+	now time.Time
+}
+
+func (suite *FreshnessSuite) SetupTest() {
+	suite.now = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+}
+
+// newHeader creates an http.Header from name/value pairs.  Names may be repeated.
+func (suite *FreshnessSuite) newHeader(nvs ...string) http.Header {
+	suite.Require().Zero(len(nvs) % 2)
+	h := make(http.Header)
+	for i := 0; i < len(nvs); i += 2 {
+		h.Add(nvs[i], nvs[i+1])
+	}
+
+	return h
+}
+
+func (suite *FreshnessSuite) httpTime(d time.Duration) string {
+	return suite.now.Add(d).Format(http.TimeFormat)
+}
+
+func (suite *FreshnessSuite) TestParseDeltaSeconds() {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "0", expected: 0, ok: true},
+		{value: "100", expected: 100 * time.Second, ok: true},
+		{value: `"100"`, expected: 100 * time.Second, ok: true},
+		{value: "99999999999999999999999", expected: maxDeltaSeconds * time.Second, ok: true},
+		{value: "-1"},
+		{value: "abc"},
+		{value: ""},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.value, func() {
+			actual, ok := parseDeltaSeconds(testCase.value)
+			suite.Equal(testCase.expected, actual)
+			suite.Equal(testCase.ok, ok)
+		})
+	}
+}
+
+func (suite *FreshnessSuite) TestComputeFreshness() {
+	testCases := []struct {
+		name     string
+		header   http.Header
+		shared   bool
+		expected freshness
+		ok       bool
+	}{
+		{
+			name:   "None",
+			header: suite.newHeader(),
+		},
+		{
+			name:     "MaxAge",
+			header:   suite.newHeader("Cache-Control", "max-age=100"),
+			expected: freshness{ttl: 100 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "MaxAge/Duplicate",
+			header:   suite.newHeader("Cache-Control", "max-age=100", "Cache-Control", "MAX-AGE=200"),
+			expected: freshness{ttl: 100 * time.Second},
+			ok:       true,
+		},
+		{
+			name:   "MaxAge/Invalid",
+			header: suite.newHeader("Cache-Control", "max-age=abc"),
+		},
+		{
+			name:     "MaxAge/Zero",
+			header:   suite.newHeader("Cache-Control", "max-age=0"),
+			expected: freshness{revalidate: true},
+			ok:       true,
+		},
+		{
+			name:     "MaxAge/Age",
+			header:   suite.newHeader("Cache-Control", "max-age=100", "Age", "30"),
+			expected: freshness{ttl: 70 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "MaxAge/Date",
+			header:   suite.newHeader("Cache-Control", "max-age=100", "Age", "10", "Date", suite.httpTime(-40*time.Second)),
+			expected: freshness{ttl: 60 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "MaxAge/TooOld",
+			header:   suite.newHeader("Cache-Control", "max-age=100", "Age", "300"),
+			expected: freshness{revalidate: true},
+			ok:       true,
+		},
+		{
+			name:     "NoCache",
+			header:   suite.newHeader("Cache-Control", "no-cache, max-age=100"),
+			expected: freshness{revalidate: true},
+			ok:       true,
+		},
+		{
+			name:     "NoCache/Qualified",
+			header:   suite.newHeader("Cache-Control", `no-cache="Set-Cookie, Foo", max-age=100`),
+			expected: freshness{ttl: 100 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "NoStore",
+			header:   suite.newHeader("Cache-Control", "no-store, max-age=100"),
+			expected: freshness{revalidate: true},
+			ok:       true,
+		},
+		{
+			name:     "Private",
+			header:   suite.newHeader("Cache-Control", "private, max-age=100"),
+			expected: freshness{ttl: 100 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "Private/Shared",
+			header:   suite.newHeader("Cache-Control", "private, max-age=100"),
+			shared:   true,
+			expected: freshness{revalidate: true},
+			ok:       true,
+		},
+		{
+			name:     "SMaxAge",
+			header:   suite.newHeader("Cache-Control", "max-age=100, s-maxage=200, stale-if-error=60"),
+			expected: freshness{ttl: 100 * time.Second, staleIfError: time.Minute},
+			ok:       true,
+		},
+		{
+			name:     "SMaxAge/Shared",
+			header:   suite.newHeader("Cache-Control", "max-age=100, s-maxage=200, stale-if-error=60"),
+			shared:   true,
+			expected: freshness{ttl: 200 * time.Second},
+			ok:       true,
+		},
+		{
+			name:   "Stale",
+			header: suite.newHeader("Cache-Control", "max-age=100, stale-while-revalidate=30, stale-if-error=600"),
+			expected: freshness{
+				ttl:                  100 * time.Second,
+				staleWhileRevalidate: 30 * time.Second,
+				staleIfError:         10 * time.Minute,
+			},
+			ok: true,
+		},
+		{
+			name:     "Stale/MustRevalidate",
+			header:   suite.newHeader("Cache-Control", "max-age=100, must-revalidate, stale-while-revalidate=30, stale-if-error=600"),
+			expected: freshness{ttl: 100 * time.Second},
+			ok:       true,
+		},
+		{
+			name:   "Stale/ProxyRevalidate",
+			header: suite.newHeader("Cache-Control", "max-age=100, proxy-revalidate, stale-if-error=600"),
+			expected: freshness{
+				ttl:          100 * time.Second,
+				staleIfError: 10 * time.Minute,
+			},
+			ok: true,
+		},
+		{
+			name:     "Stale/ProxyRevalidate/Shared",
+			header:   suite.newHeader("Cache-Control", "max-age=100, proxy-revalidate, stale-if-error=600"),
+			shared:   true,
+			expected: freshness{ttl: 100 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "Expires",
+			header:   suite.newHeader("Expires", suite.httpTime(time.Hour), "Date", suite.httpTime(0)),
+			expected: freshness{ttl: time.Hour},
+			ok:       true,
+		},
+		{
+			name:     "Expires/NoDate",
+			header:   suite.newHeader("Expires", suite.httpTime(time.Hour)),
+			expected: freshness{ttl: time.Hour},
+			ok:       true,
+		},
+		{
+			name:     "Expires/Age",
+			header:   suite.newHeader("Expires", suite.httpTime(time.Hour), "Age", "600"),
+			expected: freshness{ttl: 50 * time.Minute},
+			ok:       true,
+		},
+		{
+			name:     "Expires/Past",
+			header:   suite.newHeader("Expires", suite.httpTime(-time.Hour)),
+			expected: freshness{revalidate: true},
+			ok:       true,
+		},
+		{
+			name:     "Expires/Invalid",
+			header:   suite.newHeader("Expires", "0"),
+			expected: freshness{revalidate: true},
+			ok:       true,
+		},
+		{
+			name:     "Expires/MaxAgePrecedence",
+			header:   suite.newHeader("Cache-Control", "max-age=100", "Expires", suite.httpTime(time.Hour)),
+			expected: freshness{ttl: 100 * time.Second},
+			ok:       true,
+		},
+		{
+			name:   "NoLifetime",
+			header: suite.newHeader("Cache-Control", "public"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			actual, ok := computeFreshness(testCase.header, suite.now, testCase.shared)
+			suite.Equal(testCase.expected, actual)
+			suite.Equal(testCase.ok, ok)
+		})
+	}
+}
+
+func (suite *FreshnessSuite) TestParseRetryAfter() {
+	testCases := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+	}{
+		{name: "None", header: suite.newHeader()},
+		{name: "Seconds", header: suite.newHeader("Retry-After", "120"), expected: 2 * time.Minute},
+		{name: "Date", header: suite.newHeader("Retry-After", suite.httpTime(time.Hour)), expected: time.Hour},
+		{name: "Date/Past", header: suite.newHeader("Retry-After", suite.httpTime(-time.Hour))},
+		{name: "Invalid", header: suite.newHeader("Retry-After", "soon")},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			suite.Equal(testCase.expected, parseRetryAfter(testCase.header, suite.now))
+		})
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	suite.Run(t, new(FreshnessSuite))
+}
@@ -4,6 +4,8 @@
 package clortho
 
 import (
+	"errors"
+	"math"
 	"math/rand"
 	"time"
 )
@@ -20,6 +22,12 @@ type jitterer struct {
 	// to obtain the base for the jittered range.  We don't use standard
 	// jitter for TTLs since we don't want to refresh after the TTL has elapsed.
 	ttlBaseMultiplier float64
+
+	// the backoff policy applied to consecutive errors
+	backoffInitialDelay time.Duration
+	backoffMultiplier   float64
+	backoffMaxDelay     time.Duration
+	backoffMaxRetries   int
 }
 
 // newJitterer constructs a jitterer for a RefreshSource.
@@ -27,6 +35,11 @@ func newJitterer(source RefreshSource) (j jitterer) {
 	j = jitterer{
 		minInterval: source.MinInterval,
 		jitter:      source.Jitter,
+
+		backoffInitialDelay: source.Backoff.InitialDelay,
+		backoffMultiplier:   source.Backoff.Multiplier,
+		backoffMaxDelay:     source.Backoff.MaxDelay,
+		backoffMaxRetries:   source.Backoff.MaxRetries,
 	}
 
 	if j.minInterval <= 0 {
@@ -37,6 +50,18 @@ func newJitterer(source RefreshSource) (j jitterer) {
 		j.jitter = DefaultRefreshJitter
 	}
 
+	if j.backoffInitialDelay <= 0 {
+		j.backoffInitialDelay = DefaultRefreshBackoffInitialDelay
+	}
+
+	if j.backoffMultiplier < 1.0 {
+		j.backoffMultiplier = DefaultRefreshBackoffMultiplier
+	}
+
+	if j.backoffMaxDelay <= 0 {
+		j.backoffMaxDelay = DefaultRefreshBackoffMaxDelay
+	}
+
 	// precompute certain values to make computations faster
 
 	interval := source.Interval
@@ -52,15 +77,27 @@ func newJitterer(source RefreshSource) (j jitterer) {
 }
 
 // nextInterval calculates the next refresh interval given metadata and
-// any error that occurred during fetching.
+// any error that occurred during fetching.  When fetchErr is set, meta should be
+// the metadata from the last successful fetch, if any.
 func (j jitterer) nextInterval(meta ContentMeta, fetchErr error) (next time.Duration) {
-	if fetchErr != nil || meta.TTL <= 0 {
-		next = time.Duration(j.intervalBase + rand.Int63n(j.intervalRange))
-	} else {
-		// adjust the jitter window down, so that we always pick a random interval
-		// that is less than or equal to the TTL.
-		base := int64(j.ttlBaseMultiplier * float64(meta.TTL))
-		next = time.Duration(base) + time.Duration(rand.Int63n(int64(meta.TTL)-base+1))
+	switch {
+	case fetchErr != nil:
+		next = j.jitteredInterval()
+
+		// retry before the window in which the server allows stale keys to be used closes
+		if meta.StaleIfError > 0 && next > meta.StaleIfError {
+			next = j.jitteredTTL(meta.StaleIfError, 0)
+		}
+
+	case meta.Revalidate:
+		// refresh as often as allowed, which the minimum interval below takes care of
+		next = 0
+
+	case meta.TTL > 0:
+		next = j.jitteredTTL(meta.TTL, meta.StaleWhileRevalidate)
+
+	default:
+		next = j.jitteredInterval()
 	}
 
 	// enforce our minimum interval regardless of how the next interval was calculated
@@ -70,3 +107,63 @@ func (j jitterer) nextInterval(meta ContentMeta, fetchErr error) (next time.Dura
 
 	return
 }
+
+// nextRetry calculates the interval before retrying a source that has failed the given
+// number of consecutive times, which must be positive.  As with nextInterval, meta should be
+// the metadata from the last successful fetch, if any.
+//
+// The backoff delay is never longer than the interval nextInterval would produce, and it is
+// not subject to the minimum interval.  Once the retry cap is exceeded, nextInterval is used as is.
+//
+// Any Retry-After requested by an HTTP server is honored, up to the backoff's maximum delay or,
+// once the retry cap is exceeded, up to the interval from nextInterval.  A server therefore
+// cannot suspend refreshes for longer than this source is configured to wait.
+func (j jitterer) nextRetry(meta ContentMeta, fetchErr error, retries int) (next time.Duration) {
+	next = j.nextInterval(meta, fetchErr)
+	limit := next
+	if j.backoffMaxRetries <= 0 || retries <= j.backoffMaxRetries {
+		limit = j.backoffMaxDelay
+		if delay := j.backoffDelay(retries); delay < next {
+			next = delay
+		}
+	}
+
+	var hle *HTTPLoaderError
+	if errors.As(fetchErr, &hle) && next < hle.RetryAfter {
+		next = max(next, min(hle.RetryAfter, limit))
+	}
+
+	return
+}
+
+// backoffDelay computes the jittered, exponential delay for the given number of
+// consecutive retries.  The jitter window is shifted down as necessary, so that the
+// delay never exceeds the maximum.
+func (j jitterer) backoffDelay(retries int) time.Duration {
+	delay := float64(j.backoffInitialDelay) * math.Pow(j.backoffMultiplier, float64(retries-1))
+	delay = min(delay, float64(j.backoffMaxDelay)/(1.0+j.jitter))
+
+	base := int64((1.0 - j.jitter) * delay)
+	upper := int64((1.0 + j.jitter) * delay)
+	return time.Duration(base + rand.Int63n(upper-base+1))
+}
+
+// jitteredInterval computes a random interval using the standard jitter window.
+func (j jitterer) jitteredInterval() time.Duration {
+	return time.Duration(j.intervalBase + rand.Int63n(j.intervalRange))
+}
+
+// jitteredTTL computes a random interval for content with the given time-to-live.
+// The jitter window is adjusted down, so that we always pick a random interval that
+// is less than or equal to the TTL.  If the content may be used stale while it is
+// refreshed, the window may extend past the TTL into that grace period by as much as
+// it extends below the TTL.
+func (j jitterer) jitteredTTL(ttl, grace time.Duration) time.Duration {
+	base := int64(j.ttlBaseMultiplier * float64(ttl))
+	upper := int64(ttl)
+	if grace > 0 {
+		upper += min(int64(grace), upper-base)
+	}
+
+	return time.Duration(base + rand.Int63n(upper-base+1))
+}
@@ -4,6 +4,8 @@
 package clortho
 
 import (
+	"errors"
+	"math"
 	"strconv"
 	"testing"
 	"time"
@@ -56,6 +58,47 @@ func (suite *JittererSuite) TestNextInterval() {
 			expectedLo: 10 * time.Minute,
 			expectedHi: 10 * time.Minute,
 		},
+		{
+			meta: ContentMeta{
+				TTL:                  15 * time.Hour,
+				StaleWhileRevalidate: time.Hour,
+			},
+			expectedLo: time.Duration(float64(15*time.Hour) * (1.0 - (2.0 * 0.1))),
+			expectedHi: 16 * time.Hour,
+		},
+		{
+			meta: ContentMeta{
+				TTL:                  15 * time.Hour,
+				StaleWhileRevalidate: 24 * time.Hour,
+			},
+			expectedLo: time.Duration(float64(15*time.Hour) * (1.0 - (2.0 * 0.1))),
+			expectedHi: time.Duration(float64(15*time.Hour) * (1.0 + (2.0 * 0.1))),
+		},
+		{
+			meta: ContentMeta{
+				TTL:        15 * time.Hour,
+				Revalidate: true,
+			},
+			expectedLo: DefaultRefreshMinInterval,
+			expectedHi: DefaultRefreshMinInterval,
+		},
+		{
+			meta: ContentMeta{
+				TTL: 15 * time.Hour,
+			},
+			fetchErr:   errors.New("expected"),
+			expectedLo: time.Duration(float64(DefaultRefreshInterval) * (1.0 - DefaultRefreshJitter)),
+			expectedHi: time.Duration(float64(DefaultRefreshInterval) * (1.0 + DefaultRefreshJitter)),
+		},
+		{
+			meta: ContentMeta{
+				TTL:          15 * time.Hour,
+				StaleIfError: 2 * time.Hour,
+			},
+			fetchErr:   errors.New("expected"),
+			expectedLo: time.Duration(float64(2*time.Hour) * (1.0 - (2.0 * 0.1))),
+			expectedHi: 2 * time.Hour,
+		},
 	}
 
 	for i, testCase := range testCases {
@@ -71,6 +114,131 @@ func (suite *JittererSuite) TestNextInterval() {
 	}
 }
 
+func (suite *JittererSuite) TestNextRetry() {
+	// the lowest fraction of MaxDelay that a capped, jittered delay can be
+	var capRatio = (1.0 - DefaultRefreshJitter) / (1.0 + DefaultRefreshJitter)
+
+	testCases := []struct {
+		source                 RefreshSource
+		meta                   ContentMeta
+		fetchErr               error
+		retries                int
+		expectedLo, expectedHi time.Duration
+	}{
+		{
+			retries:    1,
+			expectedLo: time.Duration(float64(DefaultRefreshBackoffInitialDelay) * (1.0 - DefaultRefreshJitter)),
+			expectedHi: time.Duration(float64(DefaultRefreshBackoffInitialDelay) * (1.0 + DefaultRefreshJitter)),
+		},
+		{
+			retries:    3,
+			expectedLo: time.Duration(float64(4*DefaultRefreshBackoffInitialDelay) * (1.0 - DefaultRefreshJitter)),
+			expectedHi: time.Duration(float64(4*DefaultRefreshBackoffInitialDelay) * (1.0 + DefaultRefreshJitter)),
+		},
+		{
+			source: RefreshSource{
+				Backoff: RefreshBackoff{
+					InitialDelay: time.Minute,
+					Multiplier:   10.0,
+					MaxDelay:     5 * time.Minute,
+				},
+			},
+			// jitter never pushes the delay past MaxDelay
+			retries:    5,
+			expectedLo: time.Duration(float64(5*time.Minute) * capRatio),
+			expectedHi: 5 * time.Minute,
+		},
+		{
+			// the backoff never exceeds the normal error interval
+			meta: ContentMeta{
+				TTL:          15 * time.Hour,
+				StaleIfError: 20 * time.Minute,
+			},
+			retries:    20,
+			expectedLo: DefaultRefreshMinInterval,
+			expectedHi: 20 * time.Minute,
+		},
+		{
+			// retry cap exceeded
+			source: RefreshSource{
+				Backoff: RefreshBackoff{
+					MaxRetries: 2,
+				},
+			},
+			retries:    3,
+			expectedLo: time.Duration(float64(DefaultRefreshInterval) * (1.0 - DefaultRefreshJitter)),
+			expectedHi: time.Duration(float64(DefaultRefreshInterval) * (1.0 + DefaultRefreshJitter)),
+		},
+		{
+			fetchErr: &HTTPLoaderError{
+				StatusCode: 429,
+				RetryAfter: 5 * time.Minute,
+			},
+			retries:    1,
+			expectedLo: 5 * time.Minute,
+			expectedHi: 5 * time.Minute,
+		},
+		{
+			// Retry-After is honored even when it is longer than the normal interval
+			source: RefreshSource{
+				Interval: time.Hour,
+				Backoff: RefreshBackoff{
+					MaxDelay: 48 * time.Hour,
+				},
+			},
+			fetchErr: &HTTPLoaderError{
+				StatusCode: 503,
+				RetryAfter: 36 * time.Hour,
+			},
+			retries:    1,
+			expectedLo: 36 * time.Hour,
+			expectedHi: 36 * time.Hour,
+		},
+		{
+			// an oversized Retry-After is capped at MaxDelay
+			fetchErr: &HTTPLoaderError{
+				StatusCode: 503,
+				RetryAfter: time.Duration(math.MaxInt32+1) * time.Second,
+			},
+			retries:    1,
+			expectedLo: DefaultRefreshBackoffMaxDelay,
+			expectedHi: DefaultRefreshBackoffMaxDelay,
+		},
+		{
+			// once the retry cap is exceeded, Retry-After is capped at the normal interval
+			source: RefreshSource{
+				Backoff: RefreshBackoff{
+					MaxRetries: 1,
+				},
+			},
+			fetchErr: &HTTPLoaderError{
+				StatusCode: 429,
+				RetryAfter: time.Duration(math.MaxInt32+1) * time.Second,
+			},
+			retries:    2,
+			expectedLo: time.Duration(float64(DefaultRefreshInterval) * (1.0 - DefaultRefreshJitter)),
+			expectedHi: time.Duration(float64(DefaultRefreshInterval) * (1.0 + DefaultRefreshJitter)),
+		},
+	}
+
+	for i, testCase := range testCases {
+		suite.Run(strconv.Itoa(i), func() {
+			fetchErr := testCase.fetchErr
+			if fetchErr == nil {
+				fetchErr = errors.New("expected")
+			}
+
+			var (
+				j    = newJitterer(testCase.source)
+				next = j.nextRetry(testCase.meta, fetchErr, testCase.retries)
+			)
+
+			suite.GreaterOrEqual(next, testCase.expectedLo, "next to too low")
+			suite.GreaterOrEqual(testCase.expectedHi, next, "next is too high")
+		})
+	}
+}
+
 func TestJitterer(t *testing.T) {
 	suite.Run(t, new(JittererSuite))
 }
@@ -7,12 +7,29 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
+	"fmt"
+	"math"
+	"time"
 
+	"github.com/lestrrat-go/jwx/v2/cert"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"go.uber.org/multierr"
 )
 
+const (
+	// JWKNotBeforeField is the JWK extension field that holds the time before which a key
+	// must not be used, expressed as seconds since the epoch.
+	JWKNotBeforeField = "nbf"
+
+	// JWKExpiresAtField is the JWK extension field that holds the time at which a key
+	// expires, expressed as seconds since the epoch.
+	JWKExpiresAtField = "exp"
+)
+
 // Thumbprinter is implemented by anything that can produce a secure thumbprint of itself.
 type Thumbprinter interface {
 	// Thumbprint produces the RFC 7638 thumbprint hash, using the supplied algorithm.  The
@@ -50,23 +67,219 @@ type Key interface {
 	// Public is the public portion of the raw key.  If this key is already a public key, this method
 	// returns the same key as Raw.
 	Public() crypto.PublicKey
+
+	// NotBefore is the time before which this Key must not be used.  This corresponds to the
+	// nbf extension field of a JWK or, if that is not present, the validity of the first
+	// certificate in the x5c field.
+	//
+	// A NotBefore is optional.  This method returns the zero time if there is no lower bound.
+	NotBefore() time.Time
+
+	// ExpiresAt is the time at which this Key expires.  This corresponds to the exp extension
+	// field of a JWK or, if that is not present, the validity of the first certificate in the
+	// x5c field.
+	//
+	// An ExpiresAt is optional.  This method returns the zero time if this Key never expires.
+	ExpiresAt() time.Time
+
+	// Algorithm is the algorithm this Key is intended for, e.g. RS256.  This method
+	// corresponds to the alg field of a JWK.
+	//
+	// An Algorithm is optional.  This method can return the empty string.
+	Algorithm() string
+
+	// KeyOperations are the operations this Key is intended for, e.g. sign, verify.  This
+	// method corresponds to the key_ops field of a JWK.
+	//
+	// KeyOperations are optional.  This method can return an empty slice.
+	KeyOperations() []string
+
+	// Certificates is the X.509 certificate chain for this Key, with the certificate
+	// containing this Key first.  This method corresponds to the x5c field of a JWK.
+	// For PEM content, this is the certificate the Key was parsed from.
+	//
+	// A certificate chain is optional.  This method can return an empty slice.
+	Certificates() []*x509.Certificate
+
+	// CertificateURL is the location of the X.509 certificate chain for this Key.  This
+	// method corresponds to the x5u field of a JWK.  The URL is not fetched.
+	//
+	// A CertificateURL is optional.  This method can return the empty string.
+	CertificateURL() string
+
+	// CertificateThumbprint is the base64url-encoded SHA-1 thumbprint of this Key's
+	// X.509 certificate.  This method corresponds to the x5t field of a JWK or, if that
+	// is not present, is computed from the first certificate in the chain.
+	//
+	// This method returns the empty string if there is no certificate.
+	CertificateThumbprint() string
+
+	// CertificateThumbprintS256 is the base64url-encoded SHA-256 thumbprint of this Key's
+	// X.509 certificate.  This method corresponds to the x5t#S256 field of a JWK or, if that
+	// is not present, is computed from the first certificate in the chain.
+	//
+	// This method returns the empty string if there is no certificate.
+	CertificateThumbprintS256() string
+}
+
+// IsKeyValidAt tests if a Key may be used at the given time, according to
+// its NotBefore and ExpiresAt.
+func IsKeyValidAt(k Key, t time.Time) bool {
+	if nbf := k.NotBefore(); !nbf.IsZero() && t.Before(nbf) {
+		return false
+	}
+
+	if exp := k.ExpiresAt(); !exp.IsZero() && !t.Before(exp) {
+		return false
+	}
+
+	return true
 }
 
 type key struct {
 	Thumbprinter
-	keyID    string
-	keyType  string
-	keyUsage string
-	raw      interface{}
-	public   crypto.PublicKey
+	keyID          string
+	keyType        string
+	keyUsage       string
+	raw            interface{}
+	public         crypto.PublicKey
+	notBefore      time.Time
+	expiresAt      time.Time
+	algorithm      string
+	keyOperations  []string
+	certificates   []*x509.Certificate
+	certificateURL string
+	thumbprint     string
+	thumbprintS256 string
+}
+
+func (k *key) KeyID() string                     { return k.keyID }
+func (k *key) KeyType() string                   { return k.keyType }
+func (k *key) KeyUsage() string                  { return k.keyUsage }
+func (k *key) Raw() interface{}                  { return k.raw }
+func (k *key) Public() crypto.PublicKey          { return k.public }
+func (k *key) NotBefore() time.Time              { return k.notBefore }
+func (k *key) ExpiresAt() time.Time              { return k.expiresAt }
+func (k *key) Algorithm() string                 { return k.algorithm }
+func (k *key) CertificateURL() string            { return k.certificateURL }
+func (k *key) CertificateThumbprint() string     { return k.thumbprint }
+func (k *key) CertificateThumbprintS256() string { return k.thumbprintS256 }
+func (k *key) String() string                    { return k.keyID }
+
+func (k *key) KeyOperations() []string {
+	return append([]string{}, k.keyOperations...)
+}
+
+func (k *key) Certificates() []*x509.Certificate {
+	return append([]*x509.Certificate{}, k.certificates...)
+}
+
+// parseNumericDate converts a JWK extension field, in seconds since the epoch, into a time.
+func parseNumericDate(field string, v interface{}) (time.Time, error) {
+	type int64er interface {
+		Int64() (int64, error)
+	}
+
+	switch nd := v.(type) {
+	case float64:
+		sec, frac := math.Modf(nd)
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+
+	case int64:
+		return time.Unix(nd, 0), nil
+
+	case int:
+		return time.Unix(int64(nd), 0), nil
+
+	case int64er:
+		sec, err := nd.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Invalid JWK %s field: %w", field, err)
+		}
+
+		return time.Unix(sec, 0), nil
+
+	default:
+		return time.Time{}, fmt.Errorf("Invalid JWK %s field: %v", field, v)
+	}
 }
 
-func (k *key) KeyID() string            { return k.keyID }
-func (k *key) KeyType() string          { return k.keyType }
-func (k *key) KeyUsage() string         { return k.keyUsage }
-func (k *key) Raw() interface{}         { return k.raw }
-func (k *key) Public() crypto.PublicKey { return k.public }
-func (k *key) String() string           { return k.keyID }
+// parseCertificateChain parses each certificate in an x5c chain.  If the chain
+// is empty, this function returns nil with no error.
+func parseCertificateChain(chain *cert.Chain) (certs []*x509.Certificate, err error) {
+	if chain == nil {
+		return
+	}
+
+	for i := 0; i < chain.Len(); i++ {
+		encoded, _ := chain.Get(i)
+		der := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+		n, decodeErr := base64.StdEncoding.Decode(der, encoded)
+		if decodeErr != nil {
+			return nil, fmt.Errorf("Invalid JWK x5c field: %w", decodeErr)
+		}
+
+		c, parseErr := x509.ParseCertificate(der[:n])
+		if parseErr != nil {
+			return nil, fmt.Errorf("Invalid JWK x5c field: %w", parseErr)
+		}
+
+		certs = append(certs, c)
+	}
+
+	return
+}
+
+// setCertificates populates the certificate chain and thumbprints of k from a JWK.
+// Thumbprints not present in the JWK are computed from the first certificate.
+func (k *key) setCertificates(jk jwk.Key) (err error) {
+	if k.certificates, err = parseCertificateChain(jk.X509CertChain()); err != nil {
+		return
+	}
+
+	k.certificateURL = jk.X509URL()
+	k.thumbprint = jk.X509CertThumbprint()
+	k.thumbprintS256 = jk.X509CertThumbprintS256()
+	if len(k.certificates) > 0 {
+		leaf := k.certificates[0].Raw
+		if len(k.thumbprint) == 0 {
+			// x5t thumbprints are defined as SHA-1
+			t := sha1.Sum(leaf)
+			k.thumbprint = base64.RawURLEncoding.EncodeToString(t[:])
+		}
+
+		if len(k.thumbprintS256) == 0 {
+			t := sha256.Sum256(leaf)
+			k.thumbprintS256 = base64.RawURLEncoding.EncodeToString(t[:])
+		}
+	}
+
+	return
+}
+
+// setLifetime populates the validity window of k from a JWK.  The nbf and exp
+// extension fields take precedence over any x5c certificate.  This method must
+// be called after setCertificates.
+func (k *key) setLifetime(jk jwk.Key) (err error) {
+	if len(k.certificates) > 0 {
+		k.notBefore = k.certificates[0].NotBefore
+		k.expiresAt = k.certificates[0].NotAfter
+	}
+
+	if v, ok := jk.Get(JWKNotBeforeField); ok {
+		if k.notBefore, err = parseNumericDate(JWKNotBeforeField, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := jk.Get(JWKExpiresAtField); ok {
+		if k.expiresAt, err = parseNumericDate(JWKExpiresAtField, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
 func convertJWKKey(jk jwk.Key) (Key, error) {
 	k := &key{
@@ -80,6 +293,22 @@ func convertJWKKey(jk jwk.Key) (Key, error) {
 		return nil, err
 	}
 
+	if alg := jk.Algorithm(); alg != nil {
+		k.algorithm = alg.String()
+	}
+
+	for _, op := range jk.KeyOps() {
+		k.keyOperations = append(k.keyOperations, string(op))
+	}
+
+	if err := k.setCertificates(jk); err != nil {
+		return nil, err
+	}
+
+	if err := k.setLifetime(jk); err != nil {
+		return nil, err
+	}
+
 	type publicer interface {
 		Public() crypto.PublicKey
 	}
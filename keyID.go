@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// ErrKeyIDTooLong indicates that a key ID exceeded the maximum length allowed by a Resolver.
+	ErrKeyIDTooLong = errors.New("The key ID is too long")
+
+	// ErrKeyIDInvalidCharacters indicates that a key ID did not match the pattern required by a Resolver.
+	ErrKeyIDInvalidCharacters = errors.New("The key ID contains invalid characters")
+)
+
+// InvalidKeyIDError indicates that a Resolver rejected a key ID before attempting to resolve it.
+// Since key IDs often come from untrusted sources, such as JWT headers, the message for this
+// error does not include the key ID.
+type InvalidKeyIDError struct {
+	// KeyID is the rejected key ID.
+	KeyID string
+
+	// Err is the error returned by the KeyIDValidator.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (ikie *InvalidKeyIDError) Error() string {
+	return fmt.Sprintf("Invalid key ID: %s", ikie.Err)
+}
+
+// Unwrap returns the error from the KeyIDValidator.
+func (ikie *InvalidKeyIDError) Unwrap() error {
+	return ikie.Err
+}
+
+// KeyIDValidator is a strategy for checking key IDs before a Resolver expands them into
+// a URI template.  A validator returns a non-nil error to reject a key ID.
+type KeyIDValidator func(keyID string) error
+
+// KeyIDMaxLength returns a KeyIDValidator that rejects key IDs longer than n bytes
+// with ErrKeyIDTooLong.
+func KeyIDMaxLength(n int) KeyIDValidator {
+	return func(keyID string) error {
+		if len(keyID) > n {
+			return ErrKeyIDTooLong
+		}
+
+		return nil
+	}
+}
+
+// KeyIDMatches returns a KeyIDValidator that rejects key IDs that do not match a regular
+// expression with ErrKeyIDInvalidCharacters.  The expression should usually be anchored,
+// e.g. ^[A-Za-z0-9_-]+$, so that the entire key ID is checked.
+func KeyIDMatches(re *regexp.Regexp) KeyIDValidator {
+	return func(keyID string) error {
+		if !re.MatchString(keyID) {
+			return ErrKeyIDInvalidCharacters
+		}
+
+		return nil
+	}
+}
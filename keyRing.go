@@ -3,64 +3,672 @@
 
 package clortho
 
-import "sync"
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xmidt-org/chronon"
+	"go.uber.org/multierr"
+)
+
+// OriginKind describes how a key came to be in a KeyRing.
+type OriginKind int
+
+const (
+	// OriginAdHoc indicates a key that was supplied directly, either to NewKeyRing
+	// or to KeyRing.Add.
+	OriginAdHoc OriginKind = iota
+
+	// OriginRefresh indicates a key that was delivered by a RefreshEvent.
+	OriginRefresh
+
+	// OriginResolve indicates a key that was fetched on demand by a Resolver.
+	OriginResolve
+
+	// OriginSnapshot indicates a key that was seeded from a persisted snapshot of a
+	// refresh source.  The URI is that source's URI.  A KeyRing releases these keys once
+	// that source refreshes successfully.  See Snapshotter.
+	OriginSnapshot
+)
+
+// String returns a human-readable name for this kind.
+func (ok OriginKind) String() string {
+	switch ok {
+	case OriginAdHoc:
+		return "adhoc"
+
+	case OriginRefresh:
+		return "refresh"
+
+	case OriginResolve:
+		return "resolve"
+
+	case OriginSnapshot:
+		return "snapshot"
+
+	default:
+		return "unknown"
+	}
+}
+
+// KeyOrigin identifies one source of a key within a KeyRing.  A KeyRing keeps
+// a key for as long as at least one origin holds it.
+type KeyOrigin struct {
+	// Kind is how the key was obtained.
+	Kind OriginKind
+
+	// URI is the location the key came from.  For OriginRefresh, this is the refresh
+	// source's URI.  For OriginResolve, this is the expanded URI that was fetched.
+	// For OriginSnapshot, this is the URI of the refresh source that was persisted.
+	// For OriginAdHoc, this field is unset.
+	URI string
+}
+
+// keyOrigins is a sortable slice of KeyOrigin instances.  Sorting is
+// done by Kind, then by URI.
+type keyOrigins []KeyOrigin
+
+func (ko keyOrigins) Len() int {
+	return len(ko)
+}
+
+func (ko keyOrigins) Less(i, j int) bool {
+	if ko[i].Kind != ko[j].Kind {
+		return ko[i].Kind < ko[j].Kind
+	}
+
+	return ko[i].URI < ko[j].URI
+}
+
+func (ko keyOrigins) Swap(i, j int) {
+	ko[i], ko[j] = ko[j], ko[i]
+}
+
+// KeyRingCause describes why the contents of a KeyRing changed.
+type KeyRingCause int
+
+const (
+	// CauseOrigin indicates that an origin added or released keys, e.g. through
+	// KeyRing.Add, KeyRing.AddFrom, or a RefreshEvent.  KeyRingEvent.Origin identifies
+	// the origin.
+	CauseOrigin KeyRingCause = iota
+
+	// CauseRemove indicates that keys were removed outright with KeyRing.Remove.
+	CauseRemove
+
+	// CauseExpired indicates that keys expired, that their grace period elapsed, or
+	// that the TTL given to KeyRing.AddFromWithTTL elapsed.  In the last case,
+	// KeyRingEvent.Origin is the origin whose TTL elapsed.
+	CauseExpired
+
+	// CauseEvicted indicates that resolved keys were evicted because the ring held
+	// more than the bound set by WithMaxResolvedKeys.
+	CauseEvicted
+)
+
+// String returns a human-readable name for this cause.
+func (krc KeyRingCause) String() string {
+	switch krc {
+	case CauseOrigin:
+		return "origin"
+
+	case CauseRemove:
+		return "remove"
+
+	case CauseExpired:
+		return "expired"
+
+	case CauseEvicted:
+		return "evicted"
+
+	default:
+		return "unknown"
+	}
+}
+
+// KeyRingEvent describes a change to the keys that a KeyRing returns from Get.
+type KeyRingEvent struct {
+	// Cause is why the change happened.
+	Cause KeyRingCause
+
+	// Origin is the origin responsible for the change.  This field is only set when
+	// Cause is CauseOrigin or, for an elapsed TTL, CauseExpired.
+	Origin KeyOrigin
+
+	// Added are keys whose key IDs were not previously in the ring.
+	Added Keys
+
+	// Replaced are keys that supersede a previous key with the same key ID.  A key is
+	// only reported as replaced when its public key material differs, or cannot be
+	// compared, e.g. for symmetric keys.
+	Replaced Keys
+
+	// Removed are the keys that are no longer in the ring.  A key retained for a grace
+	// period isn't reported until that period elapses.
+	Removed Keys
+}
+
+// KeyRingListener is a sink for KeyRingEvents.
+type KeyRingListener interface {
+	// OnKeyRingEvent receives notifications for changes to a KeyRing.  This method
+	// must not panic.
+	//
+	// Events are dispatched outside the KeyRing's lock, so this method may access the
+	// KeyRing.  Events from concurrent changes may be dispatched concurrently.
+	OnKeyRingEvent(KeyRingEvent)
+}
 
 // KeyAccessor is a read-only interface to a set of keys.
 type KeyAccessor interface {
 	// Get returns the Key associated with the given key identifier (kid).
-	// If there is no such key, the second return is false.
+	// If there is no such key, the second return is false.  A key that is
+	// not yet valid or that has expired, according to its NotBefore and
+	// ExpiresAt, is treated as missing.
 	Get(keyID string) (Key, bool)
 
-	// Len returns the number of keys currently in this collection.
+	// Origins returns the origins currently holding the given key identifier (kid),
+	// sorted by kind and then URI.  If there is no such key, this method returns
+	// an empty slice.
+	Origins(keyID string) []KeyOrigin
+
+	// Len returns the number of keys currently in this collection.  This includes keys
+	// that Get treats as missing because they are not yet valid or have expired.
 	Len() int
+
+	// Keys returns a snapshot of the keys that Get would currently return, sorted by
+	// key ID.  The returned slice is a copy that the caller may modify.
+	Keys() Keys
+
+	// Filter returns the keys from Keys that match all of the given filters, sorted by
+	// key ID.  For example, Filter(ByKeyUsage("sig"), ByKeyType("EC")) returns the EC
+	// signing keys.
+	Filter(filters ...KeyFilter) Keys
+
+	// GetByThumbprint returns the Key whose RFC 7638 thumbprint, computed with the given
+	// hash, matches the given thumbprint.  Validity is checked as with Get.  If there is
+	// no such key, the second return is false.
+	//
+	// This lookup uses an index for any hash passed to WithThumbprintIndex.  For other
+	// hashes, the thumbprint of each key is computed on every call.
+	GetByThumbprint(h crypto.Hash, thumbprint []byte) (Key, bool)
+
+	// GetByCertificateThumbprint returns the Key whose X.509 certificate has the given
+	// base64url-encoded thumbprint, computed with the given hash.  The hash must be crypto.SHA1,
+	// as in the JOSE x5t header, or crypto.SHA256, as in x5t#S256.  Validity is checked as with
+	// Get.  If several keys share the certificate, the one with the lowest key ID is returned.
+	// If there is no such key, the second return is false.
+	//
+	// This lookup always uses an index.  See Key.CertificateThumbprint and Key.CertificateThumbprintS256.
+	GetByCertificateThumbprint(h crypto.Hash, thumbprint string) (Key, bool)
 }
 
 // KeyRing is a client-side cache of keys.  Implementations are always
 // safe for concurrent access.
 //
 // A KeyRing can consume events from a Refresher, which will
-// update the ring's set of keys.
+// update the ring's set of keys.  Each refresh source is tracked as a separate
+// origin, so a key deleted by one source remains in the ring if any other origin,
+// e.g. another source or an ad hoc Add, still holds it.
+//
+// Keys that have expired are evicted in the background.  Optionally, a KeyRing can
+// retain keys for a grace period after their last origin releases them.  See WithGracePeriod.
+//
+// Keys held only by OriginResolve origins can be bounded in number, with the least recently
+// used evicted first.  Keys from refresh sources and ad hoc keys are pinned and never evicted
+// this way.  See WithMaxResolvedKeys.
+//
+// For read-heavy workloads, Get can be made lock-free.  See WithCopyOnWrite.
 type KeyRing interface {
 	KeyAccessor
 	RefreshListener
 
 	// Add allows ad hoc keys to be added to this ring.  Any key that has
-	// no key ID will be skipped.
+	// no key ID will be skipped.  This is equivalent to AddFrom with OriginAdHoc.
 	//
 	// This method returns the actual count of keys added.  This will include
 	// keys already in the ring, since those will be overwritten with the new Key object.
 	Add(...Key) int
 
+	// AddFrom adds keys to this ring on behalf of the given origin.  Any key that has
+	// no key ID will be skipped.  The actual count of keys added is returned.
+	AddFrom(KeyOrigin, ...Key) int
+
+	// AddFromWithTTL is like AddFrom, except that the origin releases the keys once the
+	// given time-to-live elapses, as if the origin had deleted them.  A Resolver uses this
+	// to honor the TTL of the content it fetched.  If ttl is not positive, this method is
+	// equivalent to AddFrom.
+	AddFromWithTTL(origin KeyOrigin, ttl time.Duration, keys ...Key) int
+
 	// Remove allows add hoc keys to be removed from this ring.  Any key ID that isn't
 	// in this ring is ignored.  The actual count of deleted keys is returned.
+	//
+	// This method removes keys outright, regardless of which origins hold them
+	// and regardless of any grace period.
 	Remove(keyIDs ...string) int
+
+	// AddListener attaches a sink for KeyRingEvents.  Only changes that occur after this
+	// method call will be dispatched to the given listener.
+	AddListener(KeyRingListener) CancelListenerFunc
+}
+
+// KeyRingOption is a configurable option passed to NewKeyRingWithOptions.
+type KeyRingOption interface {
+	applyToKeyRing(*keyRing) error
+}
+
+type keyRingOptionFunc func(*keyRing) error
+
+func (krof keyRingOptionFunc) applyToKeyRing(kr *keyRing) error { return krof(kr) }
+
+// WithInitialKeys adds keys to a KeyRing when it is created.  Any key that has no
+// key ID is skipped.  Initial keys have the OriginAdHoc origin.  This option is cumulative.
+func WithInitialKeys(keys ...Key) KeyRingOption {
+	return keyRingOptionFunc(func(kr *keyRing) error {
+		kr.initialKeys = append(kr.initialKeys, keys...)
+		return nil
+	})
+}
+
+// WithGracePeriod retains keys for the given duration after their last origin
+// releases them, e.g. because every refresh source deleted them.  This allows tokens
+// signed just before a key rotation to still be verified.  A key's ExpiresAt is
+// honored regardless of any grace period.
+//
+// By default, there is no grace period.  A non-positive value also disables it.
+func WithGracePeriod(d time.Duration) KeyRingOption {
+	return keyRingOptionFunc(func(kr *keyRing) error {
+		kr.gracePeriod = d
+		return nil
+	})
+}
+
+// WithMaxResolvedKeys bounds the number of keys held only by OriginResolve origins, e.g. keys
+// added by a Resolver.  When this bound is exceeded, the least recently used of those keys
+// are removed.  Keys held by any other origin, such as a refresh source, are pinned:  they
+// don't count toward this bound and are never evicted by it.  The same is true of keys in their
+// grace period, which are removed only when that period ends.  See WithGracePeriod.
+//
+// Recency is approximate:  keys read by Get between the same two adds are equally recent.
+// In exchange, Get does not write to any state shared across keys, so this option does not
+// reintroduce contention when used with WithCopyOnWrite.
+//
+// By default, there is no bound.  A non-positive value also disables it.
+func WithMaxResolvedKeys(n int) KeyRingOption {
+	return keyRingOptionFunc(func(kr *keyRing) error {
+		kr.maxResolved = n
+		return nil
+	})
+}
+
+// WithCopyOnWrite makes Get lock-free.  After each change, the KeyRing publishes an immutable
+// copy of its keys through an atomic pointer, which Get reads without taking any lock.  All of
+// the changes from a single RefreshEvent are published together.
+//
+// This trades more expensive writes, which copy every key, for reads that don't contend with
+// each other.  It suits applications that call Get from many goroutines, e.g. to validate JWTs
+// on many cores, while keys change only on refresh.  By default, Get takes a read lock.
+func WithCopyOnWrite() KeyRingOption {
+	return keyRingOptionFunc(func(kr *keyRing) error {
+		kr.copyOnWrite = true
+		return nil
+	})
+}
+
+// WithThumbprintIndex maintains an index of the RFC 7638 thumbprint of each key, computed
+// with each of the given hashes, so that GetByThumbprint doesn't have to compute thumbprints
+// on each call.  Keys whose thumbprints can't be computed aren't indexed.  This option is
+// cumulative.
+//
+// Each hash must be available, e.g. crypto.SHA256.
+func WithThumbprintIndex(hashes ...crypto.Hash) KeyRingOption {
+	return keyRingOptionFunc(func(kr *keyRing) (err error) {
+		for _, h := range hashes {
+			if !h.Available() {
+				err = multierr.Append(err, fmt.Errorf("Thumbprint hash %s is not available", h))
+				continue
+			}
+
+			if kr.thumbprints == nil {
+				kr.thumbprints = make(map[crypto.Hash]map[string][]string, len(hashes))
+			}
+
+			if _, ok := kr.thumbprints[h]; !ok {
+				kr.thumbprints[h] = make(map[string][]string)
+			}
+		}
+
+		return
+	})
 }
 
 // NewKeyRing constructs a KeyRing with an optional set of initial keys.  Any key
-// that has no key ID is skipped.
+// that has no key ID is skipped.  Initial keys have the OriginAdHoc origin.
 func NewKeyRing(initialKeys ...Key) KeyRing {
-	kr := &keyRing{
-		keys: make(map[string]Key, len(initialKeys)),
+	kr, _ := NewKeyRingWithOptions(WithInitialKeys(initialKeys...))
+	return kr
+}
+
+// NewKeyRingWithOptions constructs a KeyRing tailored with the given options.
+func NewKeyRingWithOptions(options ...KeyRingOption) (KeyRing, error) {
+	var (
+		err error
+		kr  = &keyRing{
+			clock: chronon.SystemClock(),
+		}
+	)
+
+	for _, o := range options {
+		err = multierr.Append(err, o.applyToKeyRing(kr))
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	for _, k := range initialKeys {
-		if keyID := k.KeyID(); len(keyID) > 0 {
-			kr.keys[keyID] = k
+	kr.keys = make(map[string]*keyRingEntry, len(kr.initialKeys))
+	kr.certificates = map[crypto.Hash]map[string][]string{
+		crypto.SHA1:   make(map[string][]string),
+		crypto.SHA256: make(map[string][]string),
+	}
+
+	kr.addFrom(KeyOrigin{Kind: OriginAdHoc}, kr.initialKeys)
+	kr.initialKeys = nil
+	kr.pending = nil
+	kr.publish()
+	kr.scheduleSweep()
+
+	return kr, nil
+}
+
+// keyRingEntry tracks each origin's version of a key.  The origins map
+// serves as a reference count:  an entry is released once it has no origins.
+type keyRingEntry struct {
+	// key is the current Key, which is the one most recently added
+	key     Key
+	current KeyOrigin
+	origins map[KeyOrigin]Key
+
+	// expires holds the times when origins added with a TTL release this entry
+	expires map[KeyOrigin]time.Time
+
+	// retiredAt is when the last origin released this entry.  This is the
+	// zero time while any origin holds this entry.
+	retiredAt time.Time
+
+	// lastUsed is the owning keyRing's tick when this entry was last added or returned
+	// by Get.  See keyRing.tick.
+	lastUsed atomic.Uint64
+}
+
+// touch records that this entry was returned by Get during the given epoch.  The entry is
+// only written the first time it is used in an epoch, so that concurrent Gets of the same
+// key don't contend on it.
+func (kre *keyRingEntry) touch(epoch uint64) {
+	if kre.lastUsed.Load() < epoch {
+		kre.lastUsed.Store(epoch)
+	}
+}
+
+// set updates this entry with a key from the given origin.  If expiresAt is not
+// the zero time, the origin releases this entry at that time.
+func (kre *keyRingEntry) set(origin KeyOrigin, k Key, expiresAt time.Time) {
+	kre.key = k
+	kre.current = origin
+	kre.origins[origin] = k
+	kre.retiredAt = time.Time{}
+
+	if expiresAt.IsZero() {
+		delete(kre.expires, origin)
+	} else {
+		if kre.expires == nil {
+			kre.expires = make(map[KeyOrigin]time.Time, 1)
 		}
+
+		kre.expires[origin] = expiresAt
 	}
+}
 
-	return kr
+// resolvedOnly tests if resolves are the only origins holding this entry.  An entry in its
+// grace period is held by no origin, so it is not resolved only.
+func (kre *keyRingEntry) resolvedOnly() bool {
+	for origin := range kre.origins {
+		if origin.Kind != OriginResolve {
+			return false
+		}
+	}
+
+	return len(kre.origins) > 0
+}
+
+// release removes an origin from this entry.  If the current key belonged to that
+// origin, the key from the first remaining origin becomes current.  This method
+// returns true if no origins remain.
+func (kre *keyRingEntry) release(origin KeyOrigin) bool {
+	if _, ok := kre.origins[origin]; !ok {
+		return false
+	}
+
+	delete(kre.origins, origin)
+	delete(kre.expires, origin)
+	if len(kre.origins) == 0 {
+		return true
+	}
+
+	if kre.current == origin {
+		remaining := kre.sortedOrigins()
+		kre.current = remaining[0]
+		kre.key = kre.origins[kre.current]
+	}
+
+	return false
+}
+
+// sortedOrigins returns the origins for this entry in sorted order.
+func (kre *keyRingEntry) sortedOrigins() []KeyOrigin {
+	origins := make(keyOrigins, 0, len(kre.origins))
+	for origin := range kre.origins {
+		origins = append(origins, origin)
+	}
+
+	sort.Sort(origins)
+	return origins
+}
+
+// deadline returns the next time this entry must be swept, either because it must be evicted
+// or because an origin's TTL elapses.  If this entry never needs to be swept, the second
+// return is false.
+func (kre *keyRingEntry) deadline(gracePeriod time.Duration) (d time.Time, ok bool) {
+	d, ok = kre.evictAt(gracePeriod)
+	for _, exp := range kre.expires {
+		if !ok || exp.Before(d) {
+			d, ok = exp, true
+		}
+	}
+
+	return
+}
+
+// evictAt returns when this entry must be evicted, either because its key expires or
+// because its grace period elapses.  If this entry never needs to be evicted, the second
+// return is false.
+func (kre *keyRingEntry) evictAt(gracePeriod time.Duration) (d time.Time, ok bool) {
+	if exp := kre.key.ExpiresAt(); !exp.IsZero() {
+		d, ok = exp, true
+	}
+
+	if !kre.retiredAt.IsZero() {
+		if retired := kre.retiredAt.Add(gracePeriod); !ok || retired.Before(d) {
+			d, ok = retired, true
+		}
+	}
+
+	return
+}
+
+// keyRingView is an immutable copy of the current keys of a keyRing, which Get reads
+// without locking when copy-on-write is enabled.
+type keyRingView map[string]keyRingViewEntry
+
+// keyRingViewEntry is the state of a keyRingEntry that Get needs.
+type keyRingViewEntry struct {
+	key     Key
+	evictAt time.Time
+	evict   bool
+
+	// entry is only used to update lastUsed, which is safe for concurrent access
+	entry *keyRingEntry
 }
 
 // keyRing is the internal KeyRing implementation.
 type keyRing struct {
+	initialKeys []Key
+	gracePeriod time.Duration
+	maxResolved int
+	copyOnWrite bool
+	clock       chronon.Clock
+
+	// view is the most recently published copy of the keys.  This is only
+	// maintained when copyOnWrite is set.
+	view atomic.Pointer[keyRingView]
+
+	// tick orders entries by use, for evicting the least recently used resolved keys.  Only
+	// adds advance it, by two, with each added entry taking the odd value in between.  Get
+	// never writes the tick:  it marks entries with the current, even value.  So recency is
+	// approximate, as entries read between the same pair of adds are equally recent, but
+	// every Get is more recent than any add before it.
+	tick atomic.Uint64
+
+	listeners listeners
+
+	// pending holds the events for changes made under the write lock, which are
+	// dispatched once the lock is released
+	pending []KeyRingEvent
+
 	lock sync.RWMutex
-	keys map[string]Key
+	keys map[string]*keyRingEntry
+
+	// seeded holds the URIs of refresh sources whose snapshot keys haven't yet been
+	// released by a successful refresh
+	seeded map[string]bool
+
+	// thumbprints indexes the key IDs of the current keys by their thumbprint,
+	// for each hash passed to WithThumbprintIndex
+	thumbprints map[crypto.Hash]map[string][]string
+
+	// certificates indexes the key IDs of the current keys by their certificate thumbprint,
+	// for crypto.SHA1 and crypto.SHA256
+	certificates map[crypto.Hash]map[string][]string
+
+	// the pending background sweep, if any
+	sweepAt   time.Time
+	sweepStop chan struct{}
+}
+
+// get returns the current key for the given key ID, unless it must be evicted.  The key's
+// validity is not checked.  This method must be called under the read lock.
+func (kr *keyRing) get(keyID string) (k Key, ok bool) {
+	var e *keyRingEntry
+	if e, ok = kr.keys[keyID]; ok {
+		k = e.key
+		if d, evict := e.evictAt(kr.gracePeriod); evict && !kr.clock.Now().Before(d) {
+			// the background sweep hasn't gotten to this key yet
+			k, ok = nil, false
+		} else if kr.maxResolved > 0 {
+			e.touch(kr.tick.Load())
+		}
+	}
+
+	return
+}
+
+// getView is the lock-free equivalent of get, which reads the published view.
+func (kr *keyRing) getView(keyID string) (k Key, ok bool) {
+	var ve keyRingViewEntry
+	if ve, ok = (*kr.view.Load())[keyID]; ok {
+		k = ve.key
+		if ve.evict && !kr.clock.Now().Before(ve.evictAt) {
+			// the background sweep hasn't gotten to this key yet
+			k, ok = nil, false
+		} else if kr.maxResolved > 0 {
+			ve.entry.touch(kr.tick.Load())
+		}
+	}
+
+	return
 }
 
 func (kr *keyRing) Get(keyID string) (k Key, ok bool) {
+	if kr.copyOnWrite {
+		k, ok = kr.getView(keyID)
+	} else {
+		kr.lock.RLock()
+		k, ok = kr.get(keyID)
+		kr.lock.RUnlock()
+	}
+
+	if ok && !IsKeyValidAt(k, kr.clock.Now()) {
+		k, ok = nil, false
+	}
+
+	return
+}
+
+func (kr *keyRing) GetByThumbprint(h crypto.Hash, thumbprint []byte) (k Key, ok bool) {
+	now := kr.clock.Now()
+	kr.lock.RLock()
+	defer kr.lock.RUnlock()
+
+	if index, indexed := kr.thumbprints[h]; indexed {
+		for _, keyID := range index[string(thumbprint)] {
+			if k, ok = kr.get(keyID); ok && IsKeyValidAt(k, now) {
+				return
+			}
+		}
+
+		return nil, false
+	}
+
+	// there's no index for this hash, so compute each thumbprint
+	for keyID, e := range kr.keys {
+		if t, err := e.key.Thumbprint(h); err != nil || !bytes.Equal(t, thumbprint) {
+			continue
+		}
+
+		if k, ok = kr.get(keyID); ok && IsKeyValidAt(k, now) {
+			return
+		}
+	}
+
+	return nil, false
+}
+
+func (kr *keyRing) GetByCertificateThumbprint(h crypto.Hash, thumbprint string) (k Key, ok bool) {
+	now := kr.clock.Now()
+	kr.lock.RLock()
+	defer kr.lock.RUnlock()
+
+	for _, keyID := range kr.certificates[h][thumbprint] {
+		if k, ok = kr.get(keyID); ok && IsKeyValidAt(k, now) {
+			return
+		}
+	}
+
+	return nil, false
+}
+
+func (kr *keyRing) Origins(keyID string) (origins []KeyOrigin) {
 	kr.lock.RLock()
-	k, ok = kr.keys[keyID]
+	if e, ok := kr.keys[keyID]; ok {
+		origins = e.sortedOrigins()
+	} else {
+		origins = []KeyOrigin{}
+	}
+
 	kr.lock.RUnlock()
 	return
 }
@@ -72,54 +680,465 @@ func (kr *keyRing) Len() (n int) {
 	return
 }
 
+func (kr *keyRing) Keys() Keys {
+	now := kr.clock.Now()
+	kr.lock.RLock()
+	keys := make(Keys, 0, len(kr.keys))
+	for _, e := range kr.keys {
+		if d, evict := e.evictAt(kr.gracePeriod); evict && !now.Before(d) {
+			continue
+		}
+
+		if IsKeyValidAt(e.key, now) {
+			keys = append(keys, e.key)
+		}
+	}
+
+	kr.lock.RUnlock()
+	sort.Sort(keys)
+	return keys
+}
+
+func (kr *keyRing) Filter(filters ...KeyFilter) Keys {
+	return kr.Keys().Filter(filters...)
+}
+
+// certificateThumbprint returns a key's certificate thumbprint for one of the hashes
+// in the certificates index.
+func certificateThumbprint(h crypto.Hash, k Key) string {
+	if h == crypto.SHA1 {
+		return k.CertificateThumbprint()
+	}
+
+	return k.CertificateThumbprintS256()
+}
+
+// reindex updates the thumbprint indexes for a key ID whose current key changed from
+// previous to current.  Either key may be nil.  This method must be called under the
+// write lock or during construction.
+func (kr *keyRing) reindex(keyID string, previous, current Key) {
+	if previous == current {
+		return
+	}
+
+	for h, index := range kr.certificates {
+		if previous != nil {
+			if t := certificateThumbprint(h, previous); len(t) > 0 {
+				unindexKeyID(index, t, keyID)
+			}
+		}
+
+		if current != nil {
+			if t := certificateThumbprint(h, current); len(t) > 0 {
+				indexKeyID(index, t, keyID)
+			}
+		}
+	}
+
+	for h, index := range kr.thumbprints {
+		if previous != nil {
+			if t, err := previous.Thumbprint(h); err == nil {
+				unindexKeyID(index, string(t), keyID)
+			}
+		}
+
+		if current != nil {
+			if t, err := current.Thumbprint(h); err == nil {
+				indexKeyID(index, string(t), keyID)
+			}
+		}
+	}
+}
+
+// indexKeyID adds a key ID to the index under the given thumbprint.  Key IDs for a thumbprint
+// are kept sorted, since distinct key IDs can share key material.
+func indexKeyID(index map[string][]string, thumbprint, keyID string) {
+	keyIDs := index[thumbprint]
+	i := sort.SearchStrings(keyIDs, keyID)
+	if i < len(keyIDs) && keyIDs[i] == keyID {
+		return
+	}
+
+	keyIDs = append(keyIDs, "")
+	copy(keyIDs[i+1:], keyIDs[i:])
+	keyIDs[i] = keyID
+	index[thumbprint] = keyIDs
+}
+
+// unindexKeyID removes a key ID from the index under the given thumbprint.
+func unindexKeyID(index map[string][]string, thumbprint, keyID string) {
+	keyIDs := index[thumbprint]
+	i := sort.SearchStrings(keyIDs, keyID)
+	switch {
+	case i >= len(keyIDs) || keyIDs[i] != keyID:
+		return
+
+	case len(keyIDs) == 1:
+		delete(index, thumbprint)
+
+	default:
+		index[thumbprint] = append(keyIDs[:i:i], keyIDs[i+1:]...)
+	}
+}
+
+// delete removes an entry from this ring.  This method must be called under the write lock.
+func (kr *keyRing) delete(keyID string, e *keyRingEntry) {
+	delete(kr.keys, keyID)
+	kr.reindex(keyID, e.key, nil)
+}
+
+// event returns the pending event for the given cause and origin, creating it if necessary.
+// The returned pointer is only valid until the next call to this method.  This method must be
+// called under the write lock or during construction.
+func (kr *keyRing) event(cause KeyRingCause, origin KeyOrigin) *KeyRingEvent {
+	for i := range kr.pending {
+		if kr.pending[i].Cause == cause && kr.pending[i].Origin == origin {
+			return &kr.pending[i]
+		}
+	}
+
+	kr.pending = append(kr.pending, KeyRingEvent{
+		Cause:  cause,
+		Origin: origin,
+	})
+
+	return &kr.pending[len(kr.pending)-1]
+}
+
+// unlock publishes any copy-on-write view and releases the write lock, then dispatches the
+// events for any changes made while it was held.  Listeners are never invoked under the lock.
+func (kr *keyRing) unlock() {
+	kr.publish()
+	events := kr.pending
+	kr.pending = nil
+	kr.lock.Unlock()
+
+	for _, event := range events {
+		kr.listeners.visit(func(l interface{}) {
+			l.(KeyRingListener).OnKeyRingEvent(event)
+		})
+	}
+}
+
+// publish replaces the view read by Get with a copy of the current keys, if copy-on-write
+// is enabled.  This method must be called under the write lock or during construction.
+func (kr *keyRing) publish() {
+	if !kr.copyOnWrite {
+		return
+	}
+
+	view := make(keyRingView, len(kr.keys))
+	for keyID, e := range kr.keys {
+		ve := keyRingViewEntry{
+			key:   e.key,
+			entry: e,
+		}
+
+		ve.evictAt, ve.evict = e.evictAt(kr.gracePeriod)
+		view[keyID] = ve
+	}
+
+	kr.view.Store(&view)
+}
+
+// sameKey tests if two keys have the same public key material.  Keys whose material can't
+// be compared, such as symmetric keys, are only the same if they are the same object.
+func sameKey(a, b Key) bool {
+	if a == b {
+		return true
+	}
+
+	if p, ok := a.Public().(interface{ Equal(crypto.PublicKey) bool }); ok {
+		return p.Equal(b.Public())
+	}
+
+	return false
+}
+
+// addFrom adds keys on behalf of an origin.  This method must be called under the write lock
+// or during construction.
+func (kr *keyRing) addFrom(origin KeyOrigin, keys []Key) int {
+	return kr.addFromUntil(origin, time.Time{}, keys)
+}
+
+// addFromUntil adds keys on behalf of an origin, which releases them at expiresAt unless
+// that is the zero time.  This method must be called under the write lock or during construction.
+func (kr *keyRing) addFromUntil(origin KeyOrigin, expiresAt time.Time, keys []Key) (n int) {
+	if origin.Kind == OriginSnapshot && len(keys) > 0 {
+		if kr.seeded == nil {
+			kr.seeded = make(map[string]bool)
+		}
+
+		kr.seeded[origin.URI] = true
+	}
+
+	for _, newKey := range keys {
+		keyID := newKey.KeyID()
+		if len(keyID) == 0 {
+			continue
+		}
+
+		e, ok := kr.keys[keyID]
+		if !ok {
+			e = &keyRingEntry{
+				origins: make(map[KeyOrigin]Key, 1),
+			}
+
+			kr.keys[keyID] = e
+		}
+
+		n++
+		previous := e.key
+		e.set(origin, newKey, expiresAt)
+		e.lastUsed.Store(kr.tick.Add(2) - 1)
+		kr.reindex(keyID, previous, newKey)
+
+		if !ok {
+			event := kr.event(CauseOrigin, origin)
+			event.Added = append(event.Added, newKey)
+		} else if !sameKey(previous, newKey) {
+			event := kr.event(CauseOrigin, origin)
+			event.Replaced = append(event.Replaced, newKey)
+		}
+	}
+
+	return
+}
+
+// evictResolved removes the least recently used keys held only by resolves until there
+// are no more than the configured maximum.  This method must be called under the write lock.
+func (kr *keyRing) evictResolved() {
+	if kr.maxResolved <= 0 {
+		return
+	}
+
+	type candidate struct {
+		keyID    string
+		lastUsed uint64
+	}
+
+	candidates := make([]candidate, 0, len(kr.keys))
+	for keyID, e := range kr.keys {
+		if e.resolvedOnly() {
+			candidates = append(candidates, candidate{keyID: keyID, lastUsed: e.lastUsed.Load()})
+		}
+	}
+
+	excess := len(candidates) - kr.maxResolved
+	if excess <= 0 {
+		return
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastUsed < candidates[j].lastUsed
+	})
+
+	event := kr.event(CauseEvicted, KeyOrigin{})
+	for _, c := range candidates[:excess] {
+		e := kr.keys[c.keyID]
+		event.Removed = append(event.Removed, e.key)
+		kr.delete(c.keyID, e)
+	}
+}
+
+// release removes an origin from a key.  Once no origins hold the key, it is either
+// deleted or retained for the grace period.  Any resulting change is recorded with the
+// given cause.  This method must be called under the write lock.
+func (kr *keyRing) release(cause KeyRingCause, origin KeyOrigin, keyID string) {
+	e, ok := kr.keys[keyID]
+	if !ok {
+		return
+	}
+
+	previous := e.key
+	switch {
+	case !e.release(origin):
+		kr.reindex(keyID, previous, e.key)
+		if !sameKey(previous, e.key) {
+			event := kr.event(cause, origin)
+			event.Replaced = append(event.Replaced, e.key)
+		}
+
+	case kr.gracePeriod > 0:
+		e.retiredAt = kr.clock.Now()
+
+	default:
+		kr.delete(keyID, e)
+		event := kr.event(cause, origin)
+		event.Removed = append(event.Removed, previous)
+	}
+}
+
+// sweep releases any origins whose TTL has elapsed, then evicts any keys that have expired
+// or whose grace period has elapsed.  This method must be called under the write lock.
+func (kr *keyRing) sweep(now time.Time) {
+	for keyID, e := range kr.keys {
+		for origin, exp := range e.expires {
+			if !now.Before(exp) {
+				kr.release(CauseExpired, origin, keyID)
+			}
+		}
+
+		if _, ok := kr.keys[keyID]; !ok {
+			// releasing an origin deleted this key
+			continue
+		}
+
+		if d, evict := e.evictAt(kr.gracePeriod); evict && !now.Before(d) {
+			kr.delete(keyID, e)
+			event := kr.event(CauseExpired, KeyOrigin{})
+			event.Removed = append(event.Removed, e.key)
+		}
+	}
+}
+
+// scheduleSweep ensures that a background sweep is pending for the earliest key that
+// must be evicted.  This method must be called under the write lock or during construction.
+func (kr *keyRing) scheduleSweep() {
+	var (
+		next    time.Time
+		pending bool
+	)
+
+	for _, e := range kr.keys {
+		if d, evict := e.deadline(kr.gracePeriod); evict && (!pending || d.Before(next)) {
+			next, pending = d, true
+		}
+	}
+
+	if !pending || (kr.sweepStop != nil && !next.Before(kr.sweepAt)) {
+		// either there's nothing to evict, or an earlier sweep will handle it
+		return
+	}
+
+	if kr.sweepStop != nil {
+		close(kr.sweepStop)
+	}
+
+	var (
+		timer = kr.clock.NewTimer(next.Sub(kr.clock.Now()))
+		stop  = make(chan struct{})
+	)
+
+	kr.sweepAt = next
+	kr.sweepStop = stop
+
+	go func() {
+		select {
+		case <-stop:
+			timer.Stop()
+
+		case <-timer.C():
+			kr.lock.Lock()
+			defer kr.unlock()
+
+			if kr.sweepStop == stop {
+				kr.sweepStop = nil
+				kr.sweep(kr.clock.Now())
+				kr.scheduleSweep()
+			}
+		}
+	}()
+}
+
+// releaseSnapshot releases the keys seeded from a snapshot of the given refresh source.
+// This method must be called under the write lock.
+func (kr *keyRing) releaseSnapshot(uri string) {
+	if !kr.seeded[uri] {
+		return
+	}
+
+	delete(kr.seeded, uri)
+	origin := KeyOrigin{
+		Kind: OriginSnapshot,
+		URI:  uri,
+	}
+
+	for keyID, e := range kr.keys {
+		if _, ok := e.origins[origin]; ok {
+			kr.release(CauseOrigin, origin, keyID)
+		}
+	}
+}
+
 func (kr *keyRing) OnRefreshEvent(event RefreshEvent) {
+	if event.Err != nil {
+		return
+	}
+
+	kr.lock.RLock()
+	seeded := kr.seeded[event.URI]
+	kr.lock.RUnlock()
+
 	// check if this event represents an actual change to the set of keys
-	if event.Err != nil || (len(event.Keys) == 0 && len(event.Deleted) == 0) {
+	if !seeded && len(event.Keys) == 0 && len(event.Deleted) == 0 {
 		return
 	}
 
+	origin := KeyOrigin{
+		Kind: OriginRefresh,
+		URI:  event.URI,
+	}
+
 	kr.lock.Lock()
-	defer kr.lock.Unlock()
+	defer kr.unlock()
 
 	// reinsert all keys, not just new ones, so that we pick up any changed
 	// private key attributes
-	for _, key := range event.Keys {
-		keyID := key.KeyID()
-		if len(keyID) > 0 {
-			kr.keys[keyID] = key
-		}
-	}
+	kr.addFrom(origin, event.Keys)
+
+	// the first successful refresh supersedes any snapshot of this source.  this is
+	// done after adding the refreshed keys, so that keys in both aren't removed.
+	kr.releaseSnapshot(event.URI)
 
 	for _, key := range event.Deleted {
-		keyID := key.KeyID()
-		delete(kr.keys, keyID)
+		kr.release(CauseOrigin, origin, key.KeyID())
 	}
+
+	// a deleted key may no longer be pinned
+	kr.evictResolved()
+	kr.scheduleSweep()
 }
 
-func (kr *keyRing) Add(keys ...Key) (n int) {
-	kr.lock.Lock()
-	defer kr.lock.Unlock()
+func (kr *keyRing) Add(keys ...Key) int {
+	return kr.AddFrom(KeyOrigin{Kind: OriginAdHoc}, keys...)
+}
 
-	for _, newKey := range keys {
-		if keyID := newKey.KeyID(); len(keyID) > 0 {
-			n++
-			kr.keys[keyID] = newKey
-		}
+func (kr *keyRing) AddFrom(origin KeyOrigin, keys ...Key) int {
+	return kr.AddFromWithTTL(origin, 0, keys...)
+}
+
+func (kr *keyRing) AddFromWithTTL(origin KeyOrigin, ttl time.Duration, keys ...Key) int {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = kr.clock.Now().Add(ttl)
 	}
 
-	return
+	kr.lock.Lock()
+	defer kr.unlock()
+
+	n := kr.addFromUntil(origin, expiresAt, keys)
+	kr.evictResolved()
+	kr.scheduleSweep()
+	return n
 }
 
 func (kr *keyRing) Remove(keyIDs ...string) (n int) {
 	kr.lock.Lock()
-	defer kr.lock.Unlock()
+	defer kr.unlock()
 
 	for _, keyID := range keyIDs {
-		if _, ok := kr.keys[keyID]; ok {
+		if e, ok := kr.keys[keyID]; ok {
 			n++
-			delete(kr.keys, keyID)
+			kr.delete(keyID, e)
+			event := kr.event(CauseRemove, KeyOrigin{})
+			event.Removed = append(event.Removed, e.key)
 		}
 	}
 
 	return
 }
+
+func (kr *keyRing) AddListener(l KeyRingListener) CancelListenerFunc {
+	return kr.listeners.addListener(l)
+}
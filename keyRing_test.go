@@ -4,10 +4,19 @@
 package clortho
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/chronon"
 )
 
 type KeyRingSuite struct {
@@ -102,6 +111,731 @@ func (suite *KeyRingSuite) TestOnRefreshEvent() {
 	suite.Equal(2, kr.Len())
 }
 
+func (suite *KeyRingSuite) TestOrigins() {
+	var (
+		kr = suite.newKeyRing("A")
+
+		adHoc   = KeyOrigin{Kind: OriginAdHoc}
+		source1 = KeyOrigin{Kind: OriginRefresh, URI: "http://source1.com/keys"}
+		source2 = KeyOrigin{Kind: OriginRefresh, URI: "http://source2.com/keys"}
+		resolve = KeyOrigin{Kind: OriginResolve, URI: "http://getkeys.com/C"}
+	)
+
+	suite.Equal([]KeyOrigin{adHoc}, kr.Origins("A"))
+	suite.Empty(kr.Origins("nosuch"))
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  source1.URI,
+		Keys: suite.newStubKeys("A", "B"),
+	})
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  source2.URI,
+		Keys: suite.newStubKeys("B"),
+	})
+
+	suite.Equal(1, kr.AddFrom(resolve, suite.newStubKeys("C")...))
+	suite.Equal(3, kr.Len())
+	suite.Equal([]KeyOrigin{adHoc, source1}, kr.Origins("A"))
+	suite.Equal([]KeyOrigin{source1, source2}, kr.Origins("B"))
+	suite.Equal([]KeyOrigin{resolve}, kr.Origins("C"))
+
+	// source1 drops both keys, but other origins still hold them
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     source1.URI,
+		Keys:    suite.newStubKeys("D"),
+		Deleted: suite.newStubKeys("A", "B"),
+	})
+
+	suite.Equal(4, kr.Len())
+	suite.Equal([]KeyOrigin{adHoc}, kr.Origins("A"))
+	suite.Equal([]KeyOrigin{source2}, kr.Origins("B"))
+	suite.Equal([]KeyOrigin{source1}, kr.Origins("D"))
+
+	// a refresh can't remove a key it never served
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     source2.URI,
+		Deleted: suite.newStubKeys("B", "C"),
+	})
+
+	suite.Equal(3, kr.Len())
+	_, ok := kr.Get("B")
+	suite.False(ok)
+	_, ok = kr.Get("C")
+	suite.True(ok)
+
+	// Remove ignores origins
+	suite.Equal(1, kr.Remove("C"))
+	suite.Empty(kr.Origins("C"))
+}
+
+func (suite *KeyRingSuite) TestCurrentKey() {
+	var (
+		kr = suite.newKeyRing()
+
+		first  = &key{keyID: "A", keyType: "RSA"}
+		second = &key{keyID: "A", keyType: "EC"}
+	)
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  "http://source1.com/keys",
+		Keys: []Key{first},
+	})
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  "http://source2.com/keys",
+		Keys: []Key{second},
+	})
+
+	k, ok := kr.Get("A")
+	suite.True(ok)
+	suite.Same(second, k)
+
+	// when the current key's origin releases it, the remaining origin's key is used
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     "http://source2.com/keys",
+		Deleted: []Key{second},
+	})
+
+	k, ok = kr.Get("A")
+	suite.True(ok)
+	suite.Same(first, k)
+}
+
+// newClockFor creates a fake clock for the given KeyRing and returns that clock.
+// This method should be called before any keys with lifetimes are added.
+func (suite *KeyRingSuite) newClockFor(kr KeyRing) *chronon.FakeClock {
+	suite.Require().IsType((*keyRing)(nil), kr)
+	fc := chronon.NewFakeClock(time.Now())
+	kr.(*keyRing).clock = fc
+	return fc
+}
+
+func (suite *KeyRingSuite) getTimer(ch <-chan chronon.FakeTimer) (timer chronon.FakeTimer) {
+	select {
+	case <-time.After(2 * time.Second):
+		suite.Fail("No sweep was scheduled")
+	case timer = <-ch:
+		// passing
+	}
+
+	return
+}
+
+func (suite *KeyRingSuite) TestLifetime() {
+	var (
+		kr      = suite.newKeyRing()
+		fc      = suite.newClockFor(kr)
+		timerCh = make(chan chronon.FakeTimer, 1)
+	)
+
+	fc.NotifyOnTimer(timerCh)
+	suite.Equal(2, kr.Add(
+		&key{keyID: "A", notBefore: fc.Now().Add(time.Minute)},
+		&key{keyID: "B", expiresAt: fc.Now().Add(time.Hour)},
+	))
+
+	// A isn't valid yet
+	_, ok := kr.Get("A")
+	suite.False(ok)
+	suite.assertHasKeys(kr, "B")
+	suite.Equal(2, kr.Len())
+
+	timer := suite.getTimer(timerCh)
+	suite.Equal(fc.Now().Add(time.Hour), timer.When())
+
+	fc.Set(fc.Now().Add(time.Minute))
+	suite.assertHasKeys(kr, "A", "B")
+
+	// B expires and is evicted by the background sweep
+	fc.Set(timer.When())
+	suite.Eventually(
+		func() bool { return kr.Len() == 1 },
+		2*time.Second,
+		10*time.Millisecond,
+	)
+
+	_, ok = kr.Get("B")
+	suite.False(ok)
+	suite.assertHasKeys(kr, "A")
+}
+
+func (suite *KeyRingSuite) TestGracePeriod() {
+	kr, err := NewKeyRingWithOptions(WithGracePeriod(time.Minute))
+	suite.Require().NoError(err)
+
+	var (
+		fc      = suite.newClockFor(kr)
+		timerCh = make(chan chronon.FakeTimer, 1)
+	)
+
+	fc.NotifyOnTimer(timerCh)
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Keys: suite.newStubKeys("A", "B"),
+	})
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     "http://getkeys.com/keys",
+		Deleted: suite.newStubKeys("A", "B"),
+	})
+
+	// both keys are retained during the grace period
+	suite.Equal(2, kr.Len())
+	suite.assertHasKeys(kr, "A", "B")
+	suite.Empty(kr.Origins("A"))
+
+	// re-adding a key ends its retirement
+	suite.Equal(1, kr.Add(suite.newStubKeys("B")...))
+
+	fc.Set(suite.getTimer(timerCh).When())
+	suite.Eventually(
+		func() bool { return kr.Len() == 1 },
+		2*time.Second,
+		10*time.Millisecond,
+	)
+
+	_, ok := kr.Get("A")
+	suite.False(ok)
+	suite.assertHasKeys(kr, "B")
+}
+
+func (suite *KeyRingSuite) TestMaxResolvedKeys() {
+	kr, err := NewKeyRingWithOptions(WithMaxResolvedKeys(2))
+	suite.Require().NoError(err)
+
+	var (
+		source  = KeyOrigin{Kind: OriginRefresh, URI: "http://getkeys.com/keys"}
+		resolve = KeyOrigin{Kind: OriginResolve, URI: "http://getkeys.com/resolve"}
+	)
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  source.URI,
+		Keys: suite.newStubKeys("pinned"),
+	})
+
+	// a key held by a refresh source is pinned, even if also resolved
+	suite.Equal(1, kr.AddFrom(resolve, suite.newStubKeys("pinned")...))
+	suite.Equal(2, kr.AddFrom(resolve, suite.newStubKeys("A", "B")...))
+	suite.Equal(3, kr.Len())
+
+	// A is now more recently used than B
+	suite.assertHasKeys(kr, "A")
+	suite.Equal(1, kr.AddFrom(resolve, suite.newStubKeys("C")...))
+	suite.Equal(3, kr.Len())
+	suite.assertHasKeys(kr, "pinned", "A", "C")
+	_, ok := kr.Get("B")
+	suite.False(ok)
+
+	// keys read between the same two adds are equally recent, so re-add C and
+	// then read A to leave pinned as the least recently used
+	suite.Equal(1, kr.AddFrom(resolve, suite.newStubKeys("C")...))
+	suite.Equal(3, kr.Len())
+	suite.assertHasKeys(kr, "A")
+
+	// once its source deletes it, the formerly pinned key counts toward the bound
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     source.URI,
+		Deleted: suite.newStubKeys("pinned"),
+	})
+
+	suite.Equal(2, kr.Len())
+	_, ok = kr.Get("pinned")
+	suite.False(ok)
+}
+
+func (suite *KeyRingSuite) TestMaxResolvedKeysGracePeriod() {
+	kr, err := NewKeyRingWithOptions(WithMaxResolvedKeys(1), WithGracePeriod(time.Minute))
+	suite.Require().NoError(err)
+
+	var (
+		source  = KeyOrigin{Kind: OriginRefresh, URI: "http://getkeys.com/keys"}
+		resolve = KeyOrigin{Kind: OriginResolve, URI: "http://getkeys.com/resolve"}
+	)
+
+	suite.newClockFor(kr)
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  source.URI,
+		Keys: suite.newStubKeys("retired"),
+	})
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     source.URI,
+		Deleted: suite.newStubKeys("retired"),
+	})
+
+	// a key in its grace period is never the least recently used resolved key
+	suite.Equal(1, kr.AddFrom(resolve, suite.newStubKeys("A")...))
+	suite.Equal(1, kr.AddFrom(resolve, suite.newStubKeys("B")...))
+	suite.Equal(2, kr.Len())
+	suite.assertHasKeys(kr, "retired", "B")
+	_, ok := kr.Get("A")
+	suite.False(ok)
+}
+
+func (suite *KeyRingSuite) TestResolvedTTL() {
+	var (
+		kr      = suite.newKeyRing()
+		fc      = suite.newClockFor(kr)
+		timerCh = make(chan chronon.FakeTimer, 1)
+
+		source  = KeyOrigin{Kind: OriginRefresh, URI: "http://getkeys.com/keys"}
+		resolve = KeyOrigin{Kind: OriginResolve, URI: "http://getkeys.com/resolve"}
+	)
+
+	fc.NotifyOnTimer(timerCh)
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  source.URI,
+		Keys: suite.newStubKeys("B"),
+	})
+
+	suite.Equal(2, kr.AddFromWithTTL(resolve, time.Minute, suite.newStubKeys("A", "B")...))
+	suite.Equal([]KeyOrigin{source, resolve}, kr.Origins("B"))
+
+	timer := suite.getTimer(timerCh)
+	suite.Equal(fc.Now().Add(time.Minute), timer.When())
+
+	// the resolve origin releases both keys, but the refresh source still holds B
+	fc.Set(timer.When())
+	suite.Eventually(
+		func() bool { return kr.Len() == 1 },
+		2*time.Second,
+		10*time.Millisecond,
+	)
+
+	suite.assertHasKeys(kr, "B")
+	suite.Equal([]KeyOrigin{source}, kr.Origins("B"))
+}
+
+func (suite *KeyRingSuite) TestListener() {
+	kr, err := NewKeyRingWithOptions(WithMaxResolvedKeys(1))
+	suite.Require().NoError(err)
+
+	var (
+		l        = new(mockKeyRingListener)
+		cancel   = kr.AddListener(l)
+		keys     = suite.newStubKeys("A", "B", "C")
+		replaced = suite.newStubKeys("A")
+		resolved = suite.newStubKeys("D", "E")
+
+		adHoc   = KeyOrigin{Kind: OriginAdHoc}
+		source  = KeyOrigin{Kind: OriginRefresh, URI: "http://getkeys.com/keys"}
+		resolve = KeyOrigin{Kind: OriginResolve, URI: "http://getkeys.com/resolve"}
+	)
+
+	// listeners are free to access the ring
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:  CauseOrigin,
+		Origin: source,
+		Added:  Keys{keys[0], keys[1]},
+	}).Run(func(mock.Arguments) {
+		suite.Equal(2, kr.Len())
+	}).Once()
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  source.URI,
+		Keys: keys[:2],
+	})
+
+	// reinserting the same key is not a change
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:   CauseOrigin,
+		Origin:  source,
+		Added:   Keys{keys[2]},
+		Removed: Keys{keys[1]},
+	}).Once()
+
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     source.URI,
+		Keys:    Keys{keys[0], keys[2]},
+		Deleted: suite.newStubKeys("B"),
+	})
+
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:    CauseOrigin,
+		Origin:   adHoc,
+		Replaced: Keys{replaced[0]},
+	}).Once()
+
+	suite.Equal(1, kr.Add(replaced...))
+
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:  CauseOrigin,
+		Origin: resolve,
+		Added:  Keys{resolved[0], resolved[1]},
+	}).Once()
+
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:   CauseEvicted,
+		Removed: Keys{resolved[0]},
+	}).Once()
+
+	suite.Equal(2, kr.AddFrom(resolve, resolved...))
+
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:   CauseRemove,
+		Removed: Keys{keys[2]},
+	}).Once()
+
+	suite.Equal(1, kr.Remove("C", "nosuch"))
+
+	// no further events after canceling
+	cancel()
+	suite.Equal(1, kr.Remove("A"))
+	l.AssertExpectations(suite.T())
+}
+
+func (suite *KeyRingSuite) TestListenerExpired() {
+	var (
+		kr       = suite.newKeyRing()
+		fc       = suite.newClockFor(kr)
+		timerCh  = make(chan chronon.FakeTimer, 1)
+		expired  = make(chan struct{})
+		l        = new(mockKeyRingListener)
+		resolved = suite.newStubKeys("A")
+
+		resolve = KeyOrigin{Kind: OriginResolve, URI: "http://getkeys.com/resolve"}
+	)
+
+	fc.NotifyOnTimer(timerCh)
+	kr.AddListener(l)
+
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:  CauseOrigin,
+		Origin: resolve,
+		Added:  Keys{resolved[0]},
+	}).Once()
+
+	l.ExpectOnKeyRingEvent(KeyRingEvent{
+		Cause:   CauseExpired,
+		Origin:  resolve,
+		Removed: Keys{resolved[0]},
+	}).Run(func(mock.Arguments) {
+		close(expired)
+	}).Once()
+
+	suite.Equal(1, kr.AddFromWithTTL(resolve, time.Minute, resolved...))
+	fc.Set(suite.getTimer(timerCh).When())
+
+	select {
+	case <-expired:
+		// passing
+	case <-time.After(2 * time.Second):
+		suite.Fail("No expired event was dispatched")
+	}
+
+	suite.Zero(kr.Len())
+	l.AssertExpectations(suite.T())
+}
+
+// newECKey generates an EC key with the given key ID.
+func (suite *KeyRingSuite) newECKey(keyID string) Key {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+
+	jk, err := jwk.FromRaw(pk.Public())
+	suite.Require().NoError(err)
+	suite.Require().NoError(jk.Set(jwk.KeyIDKey, keyID))
+
+	k, err := convertJWKKey(jk)
+	suite.Require().NoError(err)
+	return k
+}
+
+func (suite *KeyRingSuite) TestKeys() {
+	var (
+		kr = suite.newKeyRing()
+		fc = suite.newClockFor(kr)
+	)
+
+	suite.Empty(kr.Keys())
+	suite.Equal(4, kr.Add(
+		&key{keyID: "C", keyType: "EC", keyUsage: "sig"},
+		&key{keyID: "A", keyType: "RSA", keyUsage: "sig"},
+		&key{keyID: "B", keyType: "EC", keyUsage: "enc"},
+		&key{keyID: "D", keyType: "EC", keyUsage: "sig", notBefore: fc.Now().Add(time.Minute)},
+	))
+
+	// D isn't valid yet
+	keys := kr.Keys()
+	suite.Equal([]string{"A", "B", "C"}, keys.AppendKeyIDs(nil))
+
+	// the snapshot is a copy
+	keys[0] = nil
+	suite.Equal([]string{"A", "B", "C"}, kr.Keys().AppendKeyIDs(nil))
+
+	suite.Equal([]string{"C"}, kr.Filter(ByKeyUsage("sig"), ByKeyType("EC")).AppendKeyIDs(nil))
+	suite.Equal([]string{"B", "C"}, kr.Filter(ByKeyType("EC")).AppendKeyIDs(nil))
+	suite.Empty(kr.Filter(ByAlgorithm("ES256")))
+
+	fc.Set(fc.Now().Add(time.Minute))
+	suite.Equal([]string{"C", "D"}, kr.Filter(ByKeyUsage("sig"), ByKeyType("EC")).AppendKeyIDs(nil))
+}
+
+func (suite *KeyRingSuite) testGetByThumbprint(options ...KeyRingOption) {
+	kr, err := NewKeyRingWithOptions(options...)
+	suite.Require().NoError(err)
+
+	var (
+		a = suite.newECKey("A")
+		b = suite.newECKey("B")
+	)
+
+	aThumbprint, err := a.Thumbprint(crypto.SHA256)
+	suite.Require().NoError(err)
+
+	bThumbprint, err := b.Thumbprint(crypto.SHA256)
+	suite.Require().NoError(err)
+
+	_, ok := kr.GetByThumbprint(crypto.SHA256, aThumbprint)
+	suite.False(ok)
+
+	suite.Equal(2, kr.Add(a, b))
+	k, ok := kr.GetByThumbprint(crypto.SHA256, aThumbprint)
+	suite.True(ok)
+	suite.Same(a, k)
+
+	k, ok = kr.GetByThumbprint(crypto.SHA256, bThumbprint)
+	suite.True(ok)
+	suite.Same(b, k)
+
+	// replacing B's key material changes its thumbprint
+	replacement := suite.newECKey("B")
+	suite.Equal(1, kr.Add(replacement))
+	_, ok = kr.GetByThumbprint(crypto.SHA256, bThumbprint)
+	suite.False(ok)
+
+	replacementThumbprint, err := replacement.Thumbprint(crypto.SHA256)
+	suite.Require().NoError(err)
+	k, ok = kr.GetByThumbprint(crypto.SHA256, replacementThumbprint)
+	suite.True(ok)
+	suite.Same(replacement, k)
+
+	suite.Equal(1, kr.Remove("A"))
+	_, ok = kr.GetByThumbprint(crypto.SHA256, aThumbprint)
+	suite.False(ok)
+}
+
+func (suite *KeyRingSuite) TestGetByThumbprint() {
+	suite.Run("Indexed", func() {
+		suite.testGetByThumbprint(WithThumbprintIndex(crypto.SHA256))
+	})
+
+	suite.Run("NotIndexed", func() {
+		suite.testGetByThumbprint(WithThumbprintIndex(crypto.SHA1))
+	})
+
+	suite.Run("NoIndex", func() {
+		suite.testGetByThumbprint()
+	})
+}
+
+func (suite *KeyRingSuite) TestGetByCertificateThumbprint() {
+	var (
+		kr = suite.newKeyRing()
+		fc = suite.newClockFor(kr)
+
+		// A and B share a certificate
+		a = &key{keyID: "A", thumbprint: "sha1", thumbprintS256: "s256"}
+		b = &key{keyID: "B", thumbprint: "sha1", thumbprintS256: "s256"}
+	)
+
+	_, ok := kr.GetByCertificateThumbprint(crypto.SHA256, "s256")
+	suite.False(ok)
+
+	suite.Equal(2, kr.Add(b, a))
+	k, ok := kr.GetByCertificateThumbprint(crypto.SHA256, "s256")
+	suite.True(ok)
+	suite.Same(a, k)
+
+	k, ok = kr.GetByCertificateThumbprint(crypto.SHA1, "sha1")
+	suite.True(ok)
+	suite.Same(a, k)
+
+	// the thumbprints of each hash are indexed separately
+	_, ok = kr.GetByCertificateThumbprint(crypto.SHA1, "s256")
+	suite.False(ok)
+
+	_, ok = kr.GetByCertificateThumbprint(crypto.SHA512, "s256")
+	suite.False(ok)
+
+	suite.Equal(1, kr.Remove("A"))
+	k, ok = kr.GetByCertificateThumbprint(crypto.SHA256, "s256")
+	suite.True(ok)
+	suite.Same(b, k)
+
+	// replacing B with a key that has no certificate removes it from the index
+	suite.Equal(1, kr.Add(&key{keyID: "B"}))
+	_, ok = kr.GetByCertificateThumbprint(crypto.SHA256, "s256")
+	suite.False(ok)
+
+	// expired keys are hidden, as with Get
+	suite.Equal(1, kr.Add(&key{keyID: "C", thumbprintS256: "s256", expiresAt: fc.Now().Add(time.Minute)}))
+	_, ok = kr.GetByCertificateThumbprint(crypto.SHA256, "s256")
+	suite.True(ok)
+
+	fc.Set(fc.Now().Add(time.Minute))
+	_, ok = kr.GetByCertificateThumbprint(crypto.SHA256, "s256")
+	suite.False(ok)
+}
+
+func (suite *KeyRingSuite) TestThumbprintIndexUnavailableHash() {
+	kr, err := NewKeyRingWithOptions(WithThumbprintIndex(crypto.Hash(0)))
+	suite.Error(err)
+	suite.Nil(kr)
+}
+
+func (suite *KeyRingSuite) TestCopyOnWrite() {
+	kr, err := NewKeyRingWithOptions(
+		WithCopyOnWrite(),
+		WithInitialKeys(suite.newStubKeys("A", "B")...),
+	)
+
+	suite.Require().NoError(err)
+	suite.assertHasKeys(kr, "A", "B")
+
+	var (
+		fc      = suite.newClockFor(kr)
+		timerCh = make(chan chronon.FakeTimer, 1)
+	)
+
+	fc.NotifyOnTimer(timerCh)
+	suite.Equal(1, kr.Add(&key{keyID: "C", expiresAt: fc.Now().Add(time.Hour)}))
+	suite.assertHasKeys(kr, "A", "B", "C")
+
+	suite.Equal(1, kr.Remove("A"))
+	_, ok := kr.Get("A")
+	suite.False(ok)
+
+	// all of the changes from a refresh are published together
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     "http://getkeys.com/keys",
+		Keys:    suite.newStubKeys("D", "E"),
+		Deleted: suite.newStubKeys("B"),
+	})
+
+	suite.assertHasKeys(kr, "B", "C", "D", "E")
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:     "http://getkeys.com/keys",
+		Keys:    suite.newStubKeys("D"),
+		Deleted: suite.newStubKeys("E"),
+	})
+
+	suite.assertHasKeys(kr, "B", "C", "D")
+	_, ok = kr.Get("E")
+	suite.False(ok)
+
+	// an expired key is hidden even before the background sweep publishes its eviction
+	fc.Set(suite.getTimer(timerCh).When())
+	_, ok = kr.Get("C")
+	suite.False(ok)
+
+	suite.Eventually(
+		func() bool { return kr.Len() == 2 },
+		2*time.Second,
+		10*time.Millisecond,
+	)
+
+	suite.assertHasKeys(kr, "B", "D")
+}
+
 func TestKeyRing(t *testing.T) {
 	suite.Run(t, new(KeyRingSuite))
 }
+
+// newBenchmarkKeyRing creates a KeyRing with the given number of keys, returning
+// the ring and the key IDs of its keys.
+func newBenchmarkKeyRing(b *testing.B, n int, options ...KeyRingOption) (KeyRing, []string) {
+	var (
+		keyIDs = make([]string, 0, n)
+		keys   = make([]Key, 0, n)
+	)
+
+	for i := 0; i < n; i++ {
+		keyID := fmt.Sprintf("key-%d", i)
+		keyIDs = append(keyIDs, keyID)
+		keys = append(keys, &key{keyID: keyID})
+	}
+
+	kr, err := NewKeyRingWithOptions(append(options, WithInitialKeys(keys...))...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return kr, keyIDs
+}
+
+func benchmarkKeyRingGet(b *testing.B, refresh bool, options ...KeyRingOption) {
+	kr, keyIDs := newBenchmarkKeyRing(b, 100, options...)
+	if refresh {
+		// simulate a refresh source that continuously delivers keys
+		var (
+			stop    = make(chan struct{})
+			stopped = make(chan struct{})
+			event   = RefreshEvent{
+				URI:  "http://getkeys.com/keys",
+				Keys: make(Keys, 0, 10),
+			}
+		)
+
+		for i := 0; i < 10; i++ {
+			event.Keys = append(event.Keys, &key{keyID: fmt.Sprintf("refresh-%d", i)})
+		}
+
+		go func() {
+			defer close(stopped)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					kr.OnRefreshEvent(event)
+				}
+			}
+		}()
+
+		defer func() {
+			close(stop)
+			<-stopped
+		}()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if _, ok := kr.Get(keyIDs[i%len(keyIDs)]); !ok {
+				b.Error("missing key")
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkKeyRingGet(b *testing.B) {
+	b.Run("RWMutex", func(b *testing.B) {
+		benchmarkKeyRingGet(b, false)
+	})
+
+	b.Run("CopyOnWrite", func(b *testing.B) {
+		benchmarkKeyRingGet(b, false, WithCopyOnWrite())
+	})
+
+	b.Run("CopyOnWriteMaxResolvedKeys", func(b *testing.B) {
+		benchmarkKeyRingGet(b, false, WithCopyOnWrite(), WithMaxResolvedKeys(1000))
+	})
+}
+
+func BenchmarkKeyRingGetDuringRefresh(b *testing.B) {
+	b.Run("RWMutex", func(b *testing.B) {
+		benchmarkKeyRingGet(b, true)
+	})
+
+	b.Run("CopyOnWrite", func(b *testing.B) {
+		benchmarkKeyRingGet(b, true, WithCopyOnWrite())
+	})
+
+	b.Run("CopyOnWriteMaxResolvedKeys", func(b *testing.B) {
+		benchmarkKeyRingGet(b, true, WithCopyOnWrite(), WithMaxResolvedKeys(1000))
+	})
+}
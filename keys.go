@@ -48,3 +48,76 @@ func (ks Keys) Less(i, j int) bool {
 func (ks Keys) Swap(i, j int) {
 	ks[i], ks[j] = ks[j], ks[i]
 }
+
+// KeyFilter is a predicate used to select keys.
+type KeyFilter func(Key) bool
+
+// ByKeyType selects keys with the given type, e.g. RSA.
+func ByKeyType(keyType string) KeyFilter {
+	return func(k Key) bool {
+		return k.KeyType() == keyType
+	}
+}
+
+// ByKeyUsage selects keys with the given usage, e.g. sig.
+func ByKeyUsage(keyUsage string) KeyFilter {
+	return func(k Key) bool {
+		return k.KeyUsage() == keyUsage
+	}
+}
+
+// ByAlgorithm selects keys that declare the given algorithm, e.g. RS256.
+// Keys that don't declare an algorithm are not selected.
+func ByAlgorithm(alg string) KeyFilter {
+	return func(k Key) bool {
+		return k.Algorithm() == alg
+	}
+}
+
+// ByKeyOperation selects keys that declare the given operation, e.g. verify.
+// Keys that don't declare any operations are not selected.
+func ByKeyOperation(op string) KeyFilter {
+	return func(k Key) bool {
+		for _, candidate := range k.KeyOperations() {
+			if candidate == op {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// ByCertificateThumbprint selects keys whose certificate has the given
+// base64url-encoded SHA-1 thumbprint.
+func ByCertificateThumbprint(t string) KeyFilter {
+	return func(k Key) bool {
+		return len(t) > 0 && k.CertificateThumbprint() == t
+	}
+}
+
+// ByCertificateThumbprintS256 selects keys whose certificate has the given
+// base64url-encoded SHA-256 thumbprint.
+func ByCertificateThumbprintS256(t string) KeyFilter {
+	return func(k Key) bool {
+		return len(t) > 0 && k.CertificateThumbprintS256() == t
+	}
+}
+
+// Filter returns the keys that match all of the given filters, in the same order.
+// With no filters, a copy of this collection is returned.  This collection is not modified.
+func (ks Keys) Filter(filters ...KeyFilter) Keys {
+	matches := make(Keys, 0, len(ks))
+	for _, k := range ks {
+		match := true
+		for i := 0; match && i < len(filters); i++ {
+			match = filters[i](k)
+		}
+
+		if match {
+			matches = append(matches, k)
+		}
+	}
+
+	return matches
+}
@@ -77,6 +77,24 @@ func (suite *KeysSuite) TestAppendKeyIDs() {
 	})
 }
 
+func (suite *KeysSuite) TestFilter() {
+	keys := Keys{
+		&key{keyID: "A", keyType: "RSA", keyUsage: "sig", algorithm: "RS256", keyOperations: []string{"verify"}},
+		&key{keyID: "B", keyType: "EC", keyUsage: "sig", algorithm: "ES256", thumbprint: "t1", thumbprintS256: "t256"},
+		&key{keyID: "C", keyType: "RSA", keyUsage: "enc"},
+	}
+
+	suite.Equal(keys, keys.Filter())
+	suite.Equal(Keys{keys[0], keys[2]}, keys.Filter(ByKeyType("RSA")))
+	suite.Equal(Keys{keys[0]}, keys.Filter(ByKeyType("RSA"), ByKeyUsage("sig")))
+	suite.Equal(Keys{keys[1]}, keys.Filter(ByAlgorithm("ES256")))
+	suite.Equal(Keys{keys[0]}, keys.Filter(ByKeyOperation("verify")))
+	suite.Empty(keys.Filter(ByKeyOperation("sign")))
+	suite.Equal(Keys{keys[1]}, keys.Filter(ByCertificateThumbprint("t1")))
+	suite.Equal(Keys{keys[1]}, keys.Filter(ByCertificateThumbprintS256("t256")))
+	suite.Empty(keys.Filter(ByCertificateThumbprintS256("")))
+}
+
 func TestKeys(t *testing.T) {
 	suite.Run(t, new(KeysSuite))
 }
@@ -5,6 +5,7 @@ package clortho
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -12,13 +13,20 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
 	"go.uber.org/multierr"
 )
 
+// ErrNotModified is returned by a Loader to indicate that content has not changed since
+// the ContentMeta passed to LoadContent was produced.  No content is returned along with this
+// error, but the returned ContentMeta will be updated with any new caching information.
+//
+// A Fetcher passes this error through to its caller, which should continue to use any keys
+// it obtained previously.
+var ErrNotModified = errors.New("The content has not been modified")
+
 // UnsupportedSchemeError indicates that a URI's scheme was not registered
 // and couldn't be handled by a Loader.
 type UnsupportedSchemeError struct {
@@ -44,12 +52,36 @@ func (nafe *NotAFileError) Error() string {
 type HTTPLoaderError struct {
 	Location   string
 	StatusCode int
+
+	// RetryAfter is the delay requested by the server's Retry-After header.  This field
+	// is only set for 429 (Too Many Requests) and 503 (Service Unavailable) responses.
+	RetryAfter time.Duration
+
+	// TTL is the freshness lifetime of a 404 (Not Found) response, computed from its
+	// Cache-Control or Expires headers.  A Resolver uses this to determine how long to
+	// remember that a key does not exist.  This field is only set when HasTTL is true.
+	TTL time.Duration
+
+	// HasTTL indicates that a 404 (Not Found) response carried explicit caching information.
+	// When true, a zero TTL means the response must not be cached.
+	HasTTL bool
 }
 
 func (hle *HTTPLoaderError) Error() string {
 	return fmt.Sprintf("Status code %d received from %s", hle.StatusCode, hle.Location)
 }
 
+// BodyTooLargeError indicates that an HTTP response body exceeded the maximum size
+// allowed by an HTTPLoader.
+type BodyTooLargeError struct {
+	Location    string
+	MaxBodySize int64
+}
+
+func (btle *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("Response body from %s exceeded the maximum size of %d bytes", btle.Location, btle.MaxBodySize)
+}
+
 // ContentMeta holds metadata about a piece of content.
 type ContentMeta struct {
 	// Format describes the type of key content.  This will typically be either
@@ -60,14 +92,40 @@ type ContentMeta struct {
 
 	// TTL is the length of time this content is considered current.  A Refresher will
 	// use this value to determine when to load content again.
+	//
+	// For HTTP responses, this is the freshness lifetime from the Cache-Control or Expires
+	// headers less the current age of the response as reported by the Age and Date headers.
 	TTL time.Duration
 
+	// StaleWhileRevalidate is the additional time past the TTL during which this content
+	// may still be used while it is being refreshed.  For HTTP responses, this is the
+	// stale-while-revalidate Cache-Control directive.
+	StaleWhileRevalidate time.Duration
+
+	// StaleIfError is the additional time past the TTL during which this content may
+	// still be used if refreshing it fails.  For HTTP responses, this is the stale-if-error
+	// Cache-Control directive.
+	StaleIfError time.Duration
+
+	// Revalidate indicates that this content must be refreshed before it is used again,
+	// e.g. because of a no-cache or no-store directive or because it was already stale
+	// when it was received.  A Refresher will refresh such content as often as its
+	// MinInterval allows.
+	Revalidate bool
+
 	// LastModified is the modification timestamp of the content.  For files, this will be
 	// the FileInfo.ModTime() value.  For HTTP responses, this will be the Last-Modified header.
 	//
-	// In the case of HTTP, this field is also used to supply a Last-Modified header in the
+	// In the case of HTTP, this field is also used to supply an If-Modified-Since header in the
 	// request.
 	LastModified time.Time
+
+	// ETag is the opaque entity tag of the content.  For HTTP responses, this will be the ETag
+	// header exactly as the server sent it, including any quotes or weak validator prefix.
+	//
+	// In the case of HTTP, this field is also used to supply an If-None-Match header in the
+	// request.
+	ETag string
 }
 
 // HTTPClient is the minimal interface required by a component which can handle
@@ -165,8 +223,24 @@ type HTTPLoader struct {
 	// Timeout is an optional timeout for each HTTP operation.  If unset,
 	// no timeout is used.
 	Timeout time.Duration
+
+	// SharedCache indicates whether this loader computes freshness as a shared cache
+	// would.  When true, the s-maxage, proxy-revalidate, and private Cache-Control
+	// directives are honored.  By default, this loader behaves as a private cache.
+	SharedCache bool
+
+	// MaxBodySize is the maximum number of bytes read from a response body.  This limit
+	// applies whether or not the server supplied a Content-Length, e.g. for chunked responses.
+	// A body larger than this limit results in a *BodyTooLargeError.
+	//
+	// If unset, DefaultHTTPMaxBodySize is used.
+	MaxBodySize int64
 }
 
+// DefaultHTTPMaxBodySize is the default limit on the size of HTTP response bodies
+// read by an HTTPLoader.
+const DefaultHTTPMaxBodySize int64 = 1024 * 1024
+
 func nopCancel() {}
 
 func (hl *HTTPLoader) newContext(parentCtx context.Context) (context.Context, context.CancelFunc) {
@@ -189,6 +263,10 @@ func (hl *HTTPLoader) newRequest(ctx context.Context, location string, meta Cont
 		if !meta.LastModified.IsZero() {
 			request.Header.Set("If-Modified-Since", meta.LastModified.Format(time.RFC1123))
 		}
+
+		if len(meta.ETag) > 0 {
+			request.Header.Set("If-None-Match", meta.ETag)
+		}
 	}
 
 	return
@@ -213,53 +291,63 @@ func (hl *HTTPLoader) transact(request *http.Request, meta ContentMeta) (respons
 
 	switch response.StatusCode {
 	case http.StatusNotModified:
-		// because we honor the Last-Modified header, the server
-		// can legitimately response with this status code.  we can
-		// just ignore anything in the body.
+		// because we send conditional headers, the server can legitimately
+		// respond with this status code.  we can just ignore anything in the body.
+		err = ErrNotModified
 
 	case http.StatusOK:
-		// NOTE: Content-Length is required for HTTP/1.1+
-		// we explicitly require that header here
-		cl := response.ContentLength
-		if cl > 0 {
-			data = make([]byte, cl)
-			_, err = io.ReadFull(response.Body, data)
-		}
+		data, err = hl.readBody(response)
 
 	default:
-		err = &HTTPLoaderError{
+		hle := &HTTPLoaderError{
 			Location:   response.Request.URL.String(),
 			StatusCode: response.StatusCode,
 		}
+
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable {
+			hle.RetryAfter = parseRetryAfter(response.Header, time.Now())
+		}
+
+		if response.StatusCode == http.StatusNotFound {
+			if f, ok := computeFreshness(response.Header, time.Now(), hl.SharedCache); ok {
+				hle.TTL, hle.HasTTL = f.ttl, true
+			}
+		}
+
+		err = hle
 	}
 
 	return
 }
 
-func (hl *HTTPLoader) newMeta(response *http.Response) (meta ContentMeta) {
-	meta.Format = response.Header.Get("Content-Type")
-	var err error
+// readBody reads the response body, enforcing the maximum body size.  A body with an
+// unknown length, e.g. a chunked response, is read until EOF or until the limit is exceeded.
+func (hl *HTTPLoader) readBody(response *http.Response) (data []byte, err error) {
+	maxBodySize := hl.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultHTTPMaxBodySize
+	}
 
-	if lastModified := response.Header.Get("Last-Modified"); len(lastModified) > 0 {
-		meta.LastModified, err = time.Parse(time.RFC1123, lastModified)
-		if err != nil {
-			// treat an invalid Last-Modified as if it were missing
-			meta.LastModified = time.Time{}
+	cl := response.ContentLength
+	switch {
+	case cl > maxBodySize:
+		err = &BodyTooLargeError{
+			Location:    response.Request.URL.String(),
+			MaxBodySize: maxBodySize,
 		}
-	}
 
-	// Cache-Control takes precedence over Expires, even if Cache-Control was invalid for some reason
-	if cacheControl := response.Header.Get("Cache-Control"); len(cacheControl) > 0 {
-		for _, cacheDirective := range strings.Split(cacheControl, ",") {
-			nv := strings.Split(cacheDirective, "=")
-			if strings.TrimSpace(nv[0]) == "max-age" && len(nv) > 1 {
-				// ignore an invalid max-age directive, just treat it as if there were no Cache-Control header
-				if seconds, err := strconv.Atoi(nv[1]); err == nil {
-					meta.TTL = time.Duration(seconds) * time.Second
-				}
-
-				// only use the first max-age directive, in case of duplicates
-				break
+	case cl > 0:
+		data = make([]byte, cl)
+		_, err = io.ReadFull(response.Body, data)
+
+	case cl < 0:
+		// read one byte past the limit so that we can tell if the body was too large
+		data, err = io.ReadAll(io.LimitReader(response.Body, maxBodySize+1))
+		if err == nil && int64(len(data)) > maxBodySize {
+			data = nil
+			err = &BodyTooLargeError{
+				Location:    response.Request.URL.String(),
+				MaxBodySize: maxBodySize,
 			}
 		}
 	}
@@ -267,6 +355,37 @@ func (hl *HTTPLoader) newMeta(response *http.Response) (meta ContentMeta) {
 	return
 }
 
+// newMeta produces the ContentMeta for a response.  For a 304, the supplied prev metadata
+// is updated with whatever headers the server sent, since a 304 need not repeat them.
+func (hl *HTTPLoader) newMeta(response *http.Response, prev ContentMeta) (meta ContentMeta) {
+	if response.StatusCode == http.StatusNotModified {
+		meta = prev
+	}
+
+	if contentType := response.Header.Get("Content-Type"); len(contentType) > 0 {
+		meta.Format = contentType
+	}
+
+	if etag := response.Header.Get("ETag"); len(etag) > 0 {
+		meta.ETag = etag
+	}
+
+	if lastModified := response.Header.Get("Last-Modified"); len(lastModified) > 0 {
+		// treat an invalid Last-Modified as if it were missing
+		if t, err := time.Parse(time.RFC1123, lastModified); err == nil {
+			meta.LastModified = t
+		}
+	}
+
+	// a response without any caching headers leaves the caching fields as is, which for
+	// a 304 means that the previous values are retained
+	if f, ok := computeFreshness(response.Header, time.Now(), hl.SharedCache); ok {
+		f.applyTo(&meta)
+	}
+
+	return
+}
+
 func (hl HTTPLoader) LoadContent(ctx context.Context, location string, meta ContentMeta) ([]byte, ContentMeta, error) {
 	requestCtx, cancel := hl.newContext(ctx)
 	defer cancel()
@@ -277,11 +396,16 @@ func (hl HTTPLoader) LoadContent(ctx context.Context, location string, meta Cont
 	}
 
 	response, data, err := hl.transact(request, meta)
-	if err != nil {
+	switch {
+	case errors.Is(err, ErrNotModified):
+		return nil, hl.newMeta(response, meta), err
+
+	case err != nil:
 		return nil, meta, err
-	}
 
-	return data, hl.newMeta(response), nil
+	default:
+		return data, hl.newMeta(response, ContentMeta{}), nil
+	}
 }
 
 // FileLoader is a Loader implementation that reads content from a file system.
@@ -6,8 +6,10 @@ package clortho
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"testing"
@@ -333,10 +335,65 @@ func (suite *LoaderSuite) testHTTPStatusNotModified() {
 
 	suite.Empty(content)
 	suite.Equal(ContentMeta{}, meta)
+	suite.ErrorIs(err, ErrNotModified)
+	suite.True(gock.IsDone())
+}
+
+func (suite *LoaderSuite) testHTTPETag() {
+	defer gock.Off()
+	gock.New("http://getkeys.com").
+		Get("/keys").
+		Reply(http.StatusOK).
+		BodyString(keyContent).
+		SetHeader("Content-Type", MediaTypeJWKSet).
+		SetHeader("ETag", `"v1"`)
+
+	l := suite.newLoader()
+	content, meta, err := l.LoadContent(
+		context.Background(),
+		"http://getkeys.com/keys",
+		ContentMeta{},
+	)
+
+	suite.Equal(keyContent, string(content))
+	suite.Equal(ContentMeta{Format: MediaTypeJWKSet, ETag: `"v1"`}, meta)
 	suite.NoError(err)
 	suite.True(gock.IsDone())
 }
 
+func (suite *LoaderSuite) testHTTPETagNotModified() {
+	defer gock.Off()
+	gock.New("http://getkeys.com").
+		Get("/keys").
+		MatchHeader("If-None-Match", `^W/"v1"$`).
+		Reply(http.StatusNotModified).
+		SetHeader("Cache-Control", "max-age=60")
+
+	content, meta, err := suite.newLoader().LoadContent(
+		context.Background(),
+		"http://getkeys.com/keys",
+		ContentMeta{
+			Format: MediaTypeJWKSet,
+			TTL:    time.Hour,
+			ETag:   `W/"v1"`,
+		},
+	)
+
+	// the previous metadata is retained, updated with any headers from the 304
+	suite.Empty(content)
+	suite.Equal(
+		ContentMeta{
+			Format: MediaTypeJWKSet,
+			TTL:    time.Minute,
+			ETag:   `W/"v1"`,
+		},
+		meta,
+	)
+
+	suite.ErrorIs(err, ErrNotModified)
+	suite.True(gock.IsDone())
+}
+
 func (suite *LoaderSuite) testHTTPLastModified() {
 	var (
 		// need to use UTC explicitly to avoid test noise
@@ -394,21 +451,43 @@ func (suite *LoaderSuite) testHTTPLastModifiedInvalid() {
 }
 
 func (suite *LoaderSuite) testHTTPCacheControl() {
-	const expectedTTL = 100 * time.Second
-
-	values := []string{
-		"max-age=100",
-		"no-store, max-age=100",
+	testCases := []struct {
+		cacheControl string
+		expected     ContentMeta
+	}{
+		{
+			cacheControl: "max-age=100",
+			expected: ContentMeta{
+				Format: MediaTypeJWKSet,
+				TTL:    100 * time.Second,
+			},
+		},
+		{
+			cacheControl: "max-age=100, stale-while-revalidate=30, stale-if-error=600",
+			expected: ContentMeta{
+				Format:               MediaTypeJWKSet,
+				TTL:                  100 * time.Second,
+				StaleWhileRevalidate: 30 * time.Second,
+				StaleIfError:         10 * time.Minute,
+			},
+		},
+		{
+			cacheControl: "no-store, max-age=100",
+			expected: ContentMeta{
+				Format:     MediaTypeJWKSet,
+				Revalidate: true,
+			},
+		},
 	}
 
-	for _, value := range values {
-		suite.Run(value, func() {
+	for _, testCase := range testCases {
+		suite.Run(testCase.cacheControl, func() {
 			defer gock.Off()
 			gock.New("http://getkeys.com").
 				Get("/keys").
 				Reply(http.StatusOK).
 				SetHeader("Content-Type", MediaTypeJWKSet).
-				SetHeader("Cache-Control", value).
+				SetHeader("Cache-Control", testCase.cacheControl).
 				BodyString(keyContent)
 
 			content, meta, err := suite.newLoader().LoadContent(
@@ -418,20 +497,38 @@ func (suite *LoaderSuite) testHTTPCacheControl() {
 			)
 
 			suite.Equal(keyContent, string(content))
-			suite.Equal(
-				ContentMeta{
-					Format: MediaTypeJWKSet,
-					TTL:    expectedTTL,
-				},
-				meta,
-			)
-
+			suite.Equal(testCase.expected, meta)
 			suite.NoError(err)
 			suite.True(gock.IsDone())
 		})
 	}
 }
 
+func (suite *LoaderSuite) testHTTPExpires() {
+	defer gock.Off()
+	gock.New("http://getkeys.com").
+		Get("/keys").
+		Reply(http.StatusOK).
+		SetHeader("Content-Type", MediaTypeJWKSet).
+		SetHeader("Date", "Sat, 01 Mar 2025 12:00:00 GMT").
+		SetHeader("Expires", "Sat, 01 Mar 2025 13:00:00 GMT").
+		SetHeader("Age", "600").
+		BodyString(keyContent)
+
+	content, meta, err := suite.newLoader().LoadContent(
+		context.Background(),
+		"http://getkeys.com/keys",
+		ContentMeta{},
+	)
+
+	// the apparent age from the Date header is far larger than the Expires window,
+	// so the content is already stale
+	suite.Equal(keyContent, string(content))
+	suite.Equal(ContentMeta{Format: MediaTypeJWKSet, Revalidate: true}, meta)
+	suite.NoError(err)
+	suite.True(gock.IsDone())
+}
+
 func (suite *LoaderSuite) testHTTPErrorStatus() {
 	// just a few examples of error codes that produce HTTPLoaderError
 	errorStatusCodes := []int{
@@ -466,6 +563,184 @@ func (suite *LoaderSuite) testHTTPErrorStatus() {
 	}
 }
 
+func (suite *LoaderSuite) testHTTPRetryAfter() {
+	testCases := []struct {
+		statusCode int
+		retryAfter string
+		expected   time.Duration
+	}{
+		{statusCode: http.StatusTooManyRequests, retryAfter: "120", expected: 2 * time.Minute},
+		{statusCode: http.StatusServiceUnavailable, retryAfter: "30", expected: 30 * time.Second},
+		{statusCode: http.StatusServiceUnavailable, retryAfter: "invalid"},
+		{statusCode: http.StatusServiceUnavailable},
+		{statusCode: http.StatusInternalServerError, retryAfter: "120"},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(fmt.Sprintf("%d/%s", testCase.statusCode, testCase.retryAfter), func() {
+			defer gock.Off()
+			response := gock.New("http://getkeys.com").
+				Get("/keys").
+				Reply(testCase.statusCode)
+
+			if len(testCase.retryAfter) > 0 {
+				response.SetHeader("Retry-After", testCase.retryAfter)
+			}
+
+			_, _, err := suite.newLoader().LoadContent(
+				context.Background(),
+				"http://getkeys.com/keys",
+				ContentMeta{},
+			)
+
+			var hle *HTTPLoaderError
+			suite.Require().ErrorAs(err, &hle)
+			suite.Equal(testCase.statusCode, hle.StatusCode)
+			suite.Equal(testCase.expected, hle.RetryAfter)
+		})
+	}
+}
+
+func (suite *LoaderSuite) testHTTPNotFoundTTL() {
+	testCases := []struct {
+		statusCode   int
+		cacheControl string
+		expectedTTL  time.Duration
+		expectedHas  bool
+	}{
+		{statusCode: http.StatusNotFound, cacheControl: "max-age=300", expectedTTL: 5 * time.Minute, expectedHas: true},
+		{statusCode: http.StatusNotFound, cacheControl: "no-store", expectedHas: true},
+		{statusCode: http.StatusNotFound},
+		{statusCode: http.StatusInternalServerError, cacheControl: "max-age=300"},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(fmt.Sprintf("%d/%s", testCase.statusCode, testCase.cacheControl), func() {
+			defer gock.Off()
+			response := gock.New("http://getkeys.com").
+				Get("/keys").
+				Reply(testCase.statusCode)
+
+			if len(testCase.cacheControl) > 0 {
+				response.SetHeader("Cache-Control", testCase.cacheControl)
+			}
+
+			_, _, err := suite.newLoader().LoadContent(
+				context.Background(),
+				"http://getkeys.com/keys",
+				ContentMeta{},
+			)
+
+			var hle *HTTPLoaderError
+			suite.Require().ErrorAs(err, &hle)
+			suite.Equal(testCase.statusCode, hle.StatusCode)
+			suite.Equal(testCase.expectedTTL, hle.TTL)
+			suite.Equal(testCase.expectedHas, hle.HasTTL)
+		})
+	}
+}
+
+// newChunkedServer creates a test server that writes the given content in two
+// flushed pieces, which forces a chunked response with no Content-Length.
+func (suite *LoaderSuite) newChunkedServer(content string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		response.Header().Set("Content-Type", MediaTypeJWKSet)
+		response.WriteHeader(http.StatusOK)
+
+		middle := len(content) / 2
+		response.Write([]byte(content[:middle]))
+		response.(http.Flusher).Flush()
+		response.Write([]byte(content[middle:]))
+	}))
+}
+
+func (suite *LoaderSuite) testHTTPChunked() {
+	server := suite.newChunkedServer(keyContent)
+	defer server.Close()
+
+	l := suite.newLoader(
+		WithSchemes(
+			HTTPLoader{
+				Client: server.Client(),
+			},
+			"http",
+		),
+	)
+
+	content, meta, err := l.LoadContent(
+		context.Background(),
+		server.URL+"/keys",
+		ContentMeta{},
+	)
+
+	suite.Equal(keyContent, string(content))
+	suite.Equal(ContentMeta{Format: MediaTypeJWKSet}, meta)
+	suite.NoError(err)
+}
+
+func (suite *LoaderSuite) testHTTPChunkedTooLarge() {
+	server := suite.newChunkedServer(keyContent)
+	defer server.Close()
+
+	l := suite.newLoader(
+		WithSchemes(
+			HTTPLoader{
+				Client:      server.Client(),
+				MaxBodySize: int64(len(keyContent) - 1),
+			},
+			"http",
+		),
+	)
+
+	content, meta, err := l.LoadContent(
+		context.Background(),
+		server.URL+"/keys",
+		ContentMeta{},
+	)
+
+	suite.Empty(content)
+	suite.Equal(ContentMeta{}, meta)
+
+	var btle *BodyTooLargeError
+	suite.Require().ErrorAs(err, &btle)
+	suite.Equal(server.URL+"/keys", btle.Location)
+	suite.Equal(int64(len(keyContent)-1), btle.MaxBodySize)
+	suite.Contains(btle.Error(), server.URL+"/keys")
+}
+
+func (suite *LoaderSuite) testHTTPContentLengthTooLarge() {
+	defer gock.Off()
+	gock.New("http://getkeys.com").
+		Get("/keys").
+		Reply(http.StatusOK).
+		BodyString(keyContent).
+		SetHeader("Content-Type", MediaTypeJWK)
+
+	l := suite.newLoader(
+		WithSchemes(
+			HTTPLoader{
+				MaxBodySize: 5,
+			},
+			"http",
+		),
+	)
+
+	content, meta, err := l.LoadContent(
+		context.Background(),
+		"http://getkeys.com/keys",
+		ContentMeta{},
+	)
+
+	suite.Empty(content)
+	suite.Equal(ContentMeta{}, meta)
+
+	var btle *BodyTooLargeError
+	suite.Require().ErrorAs(err, &btle)
+	suite.Equal("http://getkeys.com/keys", btle.Location)
+	suite.Equal(int64(5), btle.MaxBodySize)
+	suite.True(gock.IsDone())
+}
+
 func (suite *LoaderSuite) TestHTTPLoader() {
 	suite.Run("Simple", suite.testHTTPSimple)
 	suite.Run("ClientError", suite.testHTTPClientError)
@@ -473,10 +748,18 @@ func (suite *LoaderSuite) TestHTTPLoader() {
 	suite.Run("CustomLoader/DefaultClient", suite.testHTTPCustomLoaderDefaultClient)
 	suite.Run("CustomLoader/EncoderError", suite.testHTTPCustomLoaderEncoderError)
 	suite.Run("StatusNotModified", suite.testHTTPStatusNotModified)
+	suite.Run("ETag", suite.testHTTPETag)
+	suite.Run("ETag/NotModified", suite.testHTTPETagNotModified)
 	suite.Run("Last-Modified", suite.testHTTPLastModified)
 	suite.Run("Last-Modified/Invalid", suite.testHTTPLastModifiedInvalid)
 	suite.Run("Cache-Control", suite.testHTTPCacheControl)
+	suite.Run("Expires", suite.testHTTPExpires)
 	suite.Run("ErrorStatus", suite.testHTTPErrorStatus)
+	suite.Run("RetryAfter", suite.testHTTPRetryAfter)
+	suite.Run("NotFoundTTL", suite.testHTTPNotFoundTTL)
+	suite.Run("Chunked", suite.testHTTPChunked)
+	suite.Run("Chunked/TooLarge", suite.testHTTPChunkedTooLarge)
+	suite.Run("ContentLength/TooLarge", suite.testHTTPContentLengthTooLarge)
 }
 
 func (suite *LoaderSuite) TestCustomLoader() {
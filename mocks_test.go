@@ -49,7 +49,7 @@ func (m *mockFetcher) Fetch(ctx context.Context, location string, prev ContentMe
 		args.Error(2)
 }
 
-func (m *mockFetcher) ExpectFetch(ctx context.Context, location string, prev ContentMeta) *mock.Call {
+func (m *mockFetcher) ExpectFetch(ctx interface{}, location string, prev ContentMeta) *mock.Call {
 	return m.On("Fetch", ctx, location, prev)
 }
 
@@ -80,3 +80,15 @@ func (m *mockRefreshListener) OnRefreshEvent(event RefreshEvent) {
 func (m *mockRefreshListener) ExpectOnRefreshEvent(event RefreshEvent) *mock.Call {
 	return m.On("OnRefreshEvent", event)
 }
+
+type mockKeyRingListener struct {
+	mock.Mock
+}
+
+func (m *mockKeyRingListener) OnKeyRingEvent(event KeyRingEvent) {
+	m.Called(event)
+}
+
+func (m *mockKeyRingListener) ExpectOnKeyRingEvent(event KeyRingEvent) *mock.Call {
+	return m.On("OnKeyRingEvent", event)
+}
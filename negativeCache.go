@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"container/list"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// negativeCacheEntry records a key ID that could not be resolved.
+type negativeCacheEntry struct {
+	keyID     string
+	location  string
+	err       error
+	expiresAt time.Time
+}
+
+// negativeCache is a bounded, concurrent-safe record of key IDs that could not be resolved.
+// Entries are evicted once they expire or, when the cache is full, in the order they were added.
+type negativeCache struct {
+	lock    sync.Mutex
+	ttl     time.Duration
+	maxSize int
+
+	// entries maps key IDs onto elements of order
+	entries map[string]*list.Element
+
+	// order holds *negativeCacheEntry values, oldest first
+	order *list.List
+}
+
+// newNegativeCache creates a negativeCache with the given default TTL and maximum size.
+func newNegativeCache(ttl time.Duration, maxSize int) *negativeCache {
+	if maxSize <= 0 {
+		maxSize = DefaultResolveNegativeCacheSize
+	}
+
+	return &negativeCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element, maxSize),
+		order:   list.New(),
+	}
+}
+
+// ttlFor determines how long a resolve error should be remembered.  Only ErrKeyNotFound
+// and 404 responses are cached.  A 404 response with its own caching information uses
+// that lifetime instead of this cache's TTL.  The second return is false if err should
+// not be cached at all.
+func (nc *negativeCache) ttlFor(err error) (time.Duration, bool) {
+	var hle *HTTPLoaderError
+	switch {
+	case errors.As(err, &hle):
+		if hle.StatusCode != http.StatusNotFound {
+			return 0, false
+		}
+
+		if hle.HasTTL {
+			return hle.TTL, hle.TTL > 0
+		}
+
+		return nc.ttl, true
+
+	case errors.Is(err, ErrKeyNotFound):
+		return nc.ttl, true
+
+	default:
+		return 0, false
+	}
+}
+
+// get returns the unexpired entry for a key ID, if one exists.  An expired entry is removed.
+func (nc *negativeCache) get(keyID string, now time.Time) (entry negativeCacheEntry, ok bool) {
+	nc.lock.Lock()
+	defer nc.lock.Unlock()
+
+	var e *list.Element
+	if e, ok = nc.entries[keyID]; ok {
+		entry = *e.Value.(*negativeCacheEntry)
+		if !now.Before(entry.expiresAt) {
+			nc.remove(e)
+			entry, ok = negativeCacheEntry{}, false
+		}
+	}
+
+	return
+}
+
+// put records the result of a failed resolve, if that error is cacheable.  When the cache
+// is full, expired entries are removed first, followed by the oldest entries.
+func (nc *negativeCache) put(keyID, location string, err error, now time.Time) {
+	ttl, ok := nc.ttlFor(err)
+	if !ok {
+		return
+	}
+
+	nc.lock.Lock()
+	defer nc.lock.Unlock()
+
+	if e, exists := nc.entries[keyID]; exists {
+		nc.remove(e)
+	}
+
+	if len(nc.entries) >= nc.maxSize {
+		for e := nc.order.Front(); e != nil; {
+			next := e.Next()
+			if !now.Before(e.Value.(*negativeCacheEntry).expiresAt) {
+				nc.remove(e)
+			}
+
+			e = next
+		}
+	}
+
+	for len(nc.entries) >= nc.maxSize {
+		nc.remove(nc.order.Front())
+	}
+
+	nc.entries[keyID] = nc.order.PushBack(&negativeCacheEntry{
+		keyID:     keyID,
+		location:  location,
+		err:       err,
+		expiresAt: now.Add(ttl),
+	})
+}
+
+// remove deletes an element.  This method must be called under the lock.
+func (nc *negativeCache) remove(e *list.Element) {
+	delete(nc.entries, e.Value.(*negativeCacheEntry).keyID)
+	nc.order.Remove(e)
+}
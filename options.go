@@ -6,7 +6,9 @@ package clortho
 import (
 	"crypto"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"go.uber.org/multierr"
 )
@@ -156,6 +158,65 @@ func WithKeyIDTemplate(t string) ResolverOption {
 	})
 }
 
+// WithFallbackExpander adds an Expander that is tried when all previous templates have
+// failed to produce a key because of a missing key, a 404 response, a timeout, or a network
+// error.  Fallbacks are tried in the order they are added, after the primary template
+// established by WithKeyIDExpander or WithKeyIDTemplate.
+//
+// The timeout applies to fetches using this fallback.  If it is not positive, the timeout
+// established by WithResolveTimeout is used.
+func WithFallbackExpander(e Expander, timeout time.Duration) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		r.fallbacks = append(r.fallbacks, fallbackExpander{
+			expander: e,
+			timeout:  timeout,
+		})
+
+		return nil
+	})
+}
+
+// WithFallbackTemplate adds a URI template that is tried when all previous templates have
+// failed.  See WithFallbackExpander.
+func WithFallbackTemplate(t string, timeout time.Duration) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		e, err := NewExpander(t)
+		if err == nil {
+			err = WithFallbackExpander(e, timeout).applyToResolver(r)
+		}
+
+		return err
+	})
+}
+
+// WithRefreshOnMiss causes a Resolver to refresh the sources of the given Refresher when
+// a key is not in its KeyRing, then check the KeyRing again.  This supports identity providers
+// that only publish a key set rather than a URI for each key.  A URI template is not required
+// with this option, but if one is configured, it is tried when the refresh doesn't produce
+// the key.
+//
+// Refreshes are not forced, so each source's MinInterval is honored, and they are subject to
+// the Resolver's timeout and limits.  The KeyRing set with WithKeyRing is required, and it must
+// be a listener for the Refresher's events so that refreshed keys are added to it.
+func WithRefreshOnMiss(rf Refresher) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		r.refresher = rf
+		return nil
+	})
+}
+
+// WithKeyIDValidators adds strategies for checking key IDs before they are resolved.
+// A key ID rejected by any validator results in an *InvalidKeyIDError, and no URI
+// expansion, fetch, or event occurs.  This option is cumulative.
+//
+// By default, a Resolver does not validate key IDs.
+func WithKeyIDValidators(v ...KeyIDValidator) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		r.keyIDValidators = append(r.keyIDValidators, v...)
+		return nil
+	})
+}
+
 // WithKeyRing sets a KeyRing to act as a cache for the Resolver.
 // By default, a Resolver is not associated with any KeyRing.
 func WithKeyRing(kr KeyRing) ResolverOption {
@@ -165,6 +226,108 @@ func WithKeyRing(kr KeyRing) ResolverOption {
 	})
 }
 
+// WithResolveTimeout sets the maximum time a Resolver waits for each fetch of a key.
+// A fetch that exceeds this timeout fails with a *ResolveTimeoutError.  If d is not
+// positive, no timeout is applied, which is the default.
+//
+// This timeout is independent of the contexts passed to Resolve, which bound how long
+// each caller waits for the fetch.  A fetch is canceled early only when every caller
+// waiting on it has given up.
+func WithResolveTimeout(d time.Duration) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		r.timeout = d
+		return nil
+	})
+}
+
+// WithResolveConcurrency caps the number of fetches a Resolver performs at the same time.
+// Concurrent resolves for the same key ID always share a fetch, so this limits the number of
+// distinct key IDs being fetched.  A fetch that cannot start within the queue wait fails
+// with ErrResolveRateLimited.  See WithResolveQueueWait.
+//
+// If n is not positive, there is no cap, which is the default.
+func WithResolveConcurrency(n int) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		if n > 0 {
+			r.slots = make(chan struct{}, n)
+		} else {
+			r.slots = nil
+		}
+
+		return nil
+	})
+}
+
+// WithResolveRateLimit applies a token bucket rate limit to the fetches a Resolver performs.
+// The rate is the number of fetches per second, and burst is the number of fetches that
+// may happen at once.  Only misses, i.e. key IDs that require a fetch, consume tokens.
+// A fetch that would have to wait longer than the queue wait for a token fails with
+// ErrResolveRateLimited.  See WithResolveQueueWait.
+//
+// If rate is not positive, there is no rate limit, which is the default.  A burst less
+// than 1 is treated as 1.
+func WithResolveRateLimit(rate float64, burst int) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		if rate > 0 {
+			r.bucket = newTokenBucket(rate, burst)
+		} else {
+			r.bucket = nil
+		}
+
+		return nil
+	})
+}
+
+// WithResolveQueueWait sets the maximum time a fetch waits for the limits established by
+// WithResolveConcurrency and WithResolveRateLimit.  This wait is separate from any timeout
+// set with WithResolveTimeout.
+//
+// If d is not positive, which is the default, a fetch that cannot proceed immediately fails
+// with ErrResolveRateLimited.
+func WithResolveQueueWait(d time.Duration) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		r.maxQueueWait = d
+		return nil
+	})
+}
+
+// WithBatchWorkers sets the number of key IDs that Resolver.ResolveAll resolves concurrently.
+// If n is not positive, DefaultResolveBatchWorkers is used.
+//
+// This is a limit for each call to ResolveAll.  Use WithResolveConcurrency to limit the total
+// number of fetches across all callers.
+func WithBatchWorkers(n int) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		if n > 0 {
+			r.batchWorkers = n
+		} else {
+			r.batchWorkers = DefaultResolveBatchWorkers
+		}
+
+		return nil
+	})
+}
+
+// WithNegativeCache causes a Resolver to remember key IDs that could not be found, so that
+// repeated requests for an unknown key ID don't each result in a fetch.  The ttl is how long
+// each miss is remembered, unless a 404 response supplies its own caching information.
+// The size bounds the number of key IDs remembered.  If size is not positive,
+// DefaultResolveNegativeCacheSize is used.
+//
+// If ttl is not positive, this option disables negative caching.  By default, a Resolver
+// does not remember unknown key IDs.
+func WithNegativeCache(ttl time.Duration, size int) ResolverOption {
+	return resolverOptionFunc(func(r *resolver) error {
+		if ttl > 0 {
+			r.negativeCache = newNegativeCache(ttl, size)
+		} else {
+			r.negativeCache = nil
+		}
+
+		return nil
+	})
+}
+
 // RefresherOption is a configurable option passed to NewRefresher.
 type RefresherOption interface {
 	applyToRefresher(*refresher) error
@@ -186,6 +349,16 @@ func WithSources(sources ...RefreshSource) RefresherOption {
 	})
 }
 
+// WithRequireAllSources treats every source of a Refresher as required, which causes
+// Refresher.Start to block until each source has been successfully fetched.
+// See RefreshSource.Required.
+func WithRequireAllSources() RefresherOption {
+	return refresherOptionFunc(func(r *refresher) error {
+		r.requireAll = true
+		return nil
+	})
+}
+
 // ResolverRefresherOption is a configurable option that applies to both
 // a Refresher and a Resolver.
 type ResolverRefresherOption interface {
@@ -220,14 +393,70 @@ type configOption struct {
 }
 
 func (co configOption) applyToRefresher(r *refresher) error {
+	if co.cfg.Refresh.RequireAllSources {
+		r.requireAll = true
+	}
+
 	return WithSources(co.cfg.Refresh.Sources...).applyToRefresher(r)
 }
 
-func (co configOption) applyToResolver(r *resolver) error {
-	return WithKeyIDTemplate(co.cfg.Resolve.Template).applyToResolver(r)
+func (co configOption) applyToResolver(r *resolver) (err error) {
+	if co.cfg.Resolve.KeyIDMaxLength > 0 {
+		err = multierr.Append(err, WithKeyIDValidators(KeyIDMaxLength(co.cfg.Resolve.KeyIDMaxLength)).applyToResolver(r))
+	}
+
+	if len(co.cfg.Resolve.KeyIDPattern) > 0 {
+		re, reErr := regexp.Compile(co.cfg.Resolve.KeyIDPattern)
+		if reErr == nil {
+			reErr = WithKeyIDValidators(KeyIDMatches(re)).applyToResolver(r)
+		}
+
+		err = multierr.Append(err, reErr)
+	}
+
+	for _, fallback := range co.cfg.Resolve.Fallbacks {
+		err = multierr.Append(err, WithFallbackTemplate(fallback.Template, fallback.Timeout).applyToResolver(r))
+	}
+
+	// when refreshing on a miss, a template is optional
+	if len(co.cfg.Resolve.Template) > 0 || !co.cfg.Resolve.RefreshOnMiss {
+		err = multierr.Append(err, WithKeyIDTemplate(co.cfg.Resolve.Template).applyToResolver(r))
+	}
+
+	// the remaining fields are only applied when set, so that an unset field
+	// doesn't undo an option passed before WithConfig
+	if co.cfg.Resolve.Timeout != 0 {
+		err = multierr.Append(err, WithResolveTimeout(co.cfg.Resolve.Timeout).applyToResolver(r))
+	}
+
+	if co.cfg.Resolve.MaxConcurrency != 0 {
+		err = multierr.Append(err, WithResolveConcurrency(co.cfg.Resolve.MaxConcurrency).applyToResolver(r))
+	}
+
+	if co.cfg.Resolve.RateLimit != 0 {
+		err = multierr.Append(err, WithResolveRateLimit(co.cfg.Resolve.RateLimit, co.cfg.Resolve.RateBurst).applyToResolver(r))
+	}
+
+	if co.cfg.Resolve.MaxQueueWait != 0 {
+		err = multierr.Append(err, WithResolveQueueWait(co.cfg.Resolve.MaxQueueWait).applyToResolver(r))
+	}
+
+	if co.cfg.Resolve.BatchWorkers != 0 {
+		err = multierr.Append(err, WithBatchWorkers(co.cfg.Resolve.BatchWorkers).applyToResolver(r))
+	}
+
+	if co.cfg.Resolve.NegativeCacheTTL != 0 {
+		err = multierr.Append(err, WithNegativeCache(co.cfg.Resolve.NegativeCacheTTL, co.cfg.Resolve.NegativeCacheSize).applyToResolver(r))
+	}
+
+	return
 }
 
 // WithConfig uses a Config struct to configure a Refresher and/or Resolver.
+//
+// For a Resolver, the Timeout, MaxConcurrency, RateLimit, MaxQueueWait, BatchWorkers,
+// NegativeCacheTTL, KeyIDMaxLength, and KeyIDPattern fields are only applied when set.
+// An unset field does not override an option passed before WithConfig.
 func WithConfig(cfg Config) ResolverRefresherOption {
 	return configOption{
 		cfg: cfg,
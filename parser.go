@@ -4,13 +4,21 @@
 package clortho
 
 import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/lestrrat-go/jwx/v2/cert"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"go.uber.org/multierr"
 )
 
+// ErrNoPEMBlocks indicates that content expected to be PEM-encoded contained no PEM blocks.
+var ErrNoPEMBlocks = errors.New("No PEM blocks found")
+
 // UnsupportedFormatError indicates that a Parser cannot parse a given format.
 type UnsupportedFormatError struct {
 	Format string
@@ -84,11 +92,7 @@ func NewParser(options ...ParserOption) (Parser, error) {
 
 		jp = JWKKeyParser{}
 
-		usePEM = JWKSetParser{
-			Options: []jwk.ParseOption{
-				jwk.WithPEM(true),
-			},
-		}
+		usePEM = PEMParser{}
 
 		ps = &parsers{
 			p: map[string]Parser{
@@ -151,3 +155,71 @@ func (jsp JWKSetParser) Parse(_ string, data []byte) ([]Key, error) {
 	keys := make([]Key, 0, jwkSet.Len())
 	return appendJWKSet(jwkSet, keys)
 }
+
+// PEMParser parses content as a sequence of PEM blocks, each of which is a key or
+// an X.509 certificate.  For a certificate, the resulting Key holds the certificate's
+// public key and its NotBefore and ExpiresAt are taken from the certificate's validity.
+type PEMParser struct{}
+
+// pemCertificateType is the PEM block type for an X.509 certificate.
+const pemCertificateType = "CERTIFICATE"
+
+// Parse parses each PEM block in data into a Key.  Any content outside of PEM blocks
+// is ignored.  If data contains no PEM blocks, this method returns ErrNoPEMBlocks.
+func (pp PEMParser) Parse(_ string, data []byte) (keys []Key, err error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var (
+			jk       jwk.Key
+			blockErr error
+		)
+
+		if block.Type == pemCertificateType {
+			jk, blockErr = pp.parseCertificate(block.Bytes)
+		} else {
+			jk, blockErr = jwk.ParseKey(pem.EncodeToMemory(block), jwk.WithPEM(true))
+		}
+
+		if blockErr == nil {
+			keys, blockErr = appendJWKKey(jk, keys)
+		}
+
+		err = multierr.Append(err, blockErr)
+	}
+
+	if len(keys) == 0 && err == nil {
+		err = ErrNoPEMBlocks
+	}
+
+	return
+}
+
+// parseCertificate produces a JWK for a certificate's public key, with the certificate
+// itself in the x5c field.
+func (pp PEMParser) parseCertificate(der []byte) (jwk.Key, error) {
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	jk, err := jwk.FromRaw(c.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain cert.Chain
+	if err := chain.Add([]byte(base64.StdEncoding.EncodeToString(der))); err != nil {
+		return nil, err
+	}
+
+	if err := jk.Set(jwk.X509CertChainKey, &chain); err != nil {
+		return nil, err
+	}
+
+	return jk, nil
+}
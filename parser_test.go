@@ -6,11 +6,20 @@ package clortho
 import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -366,6 +375,101 @@ func (suite *ParserSuite) TestMIMEParameters() {
 	suite.Contains(ife.Error(), formatWithParameters)
 }
 
+// newCertificate generates a self-signed EC certificate valid over the given window.
+func (suite *ParserSuite) newCertificate(notBefore, notAfter time.Time) []byte {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pk.Public(), pk)
+	suite.Require().NoError(err)
+	return der
+}
+
+func (suite *ParserSuite) TestJWKLifetime() {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+
+	jk, err := jwk.FromRaw(pk.Public())
+	suite.Require().NoError(err)
+	suite.Require().NoError(jk.Set(JWKNotBeforeField, 1700000000))
+	suite.Require().NoError(jk.Set(JWKExpiresAtField, 1800000000))
+	suite.Require().NoError(jk.Set(jwk.AlgorithmKey, jwa.ES256))
+	suite.Require().NoError(jk.Set(jwk.KeyOpsKey, jwk.KeyOperationList{jwk.KeyOpVerify}))
+	suite.Require().NoError(jk.Set(jwk.X509URLKey, "https://getkeys.com/cert.pem"))
+
+	data, err := json.Marshal(jk)
+	suite.Require().NoError(err)
+
+	keys, err := suite.newParser().Parse(MediaTypeJWK, data)
+	suite.Require().NoError(err)
+	suite.Require().Len(keys, 1)
+	suite.Equal(time.Unix(1700000000, 0), keys[0].NotBefore())
+	suite.Equal(time.Unix(1800000000, 0), keys[0].ExpiresAt())
+	suite.Equal("ES256", keys[0].Algorithm())
+	suite.Equal([]string{"verify"}, keys[0].KeyOperations())
+	suite.Equal("https://getkeys.com/cert.pem", keys[0].CertificateURL())
+	suite.Empty(keys[0].Certificates())
+	suite.Empty(keys[0].CertificateThumbprintS256())
+
+	suite.False(IsKeyValidAt(keys[0], time.Unix(1600000000, 0)))
+	suite.True(IsKeyValidAt(keys[0], time.Unix(1700000000, 0)))
+	suite.False(IsKeyValidAt(keys[0], time.Unix(1800000000, 0)))
+}
+
+func (suite *ParserSuite) TestJWKNoLifetime() {
+	keys, err := suite.newParser().Parse(MediaTypeJWK, []byte(singleJWK))
+	suite.Require().NoError(err)
+	suite.Require().Len(keys, 1)
+	suite.True(keys[0].NotBefore().IsZero())
+	suite.True(keys[0].ExpiresAt().IsZero())
+	suite.True(IsKeyValidAt(keys[0], time.Now()))
+}
+
+func (suite *ParserSuite) TestPEMCertificate() {
+	var (
+		notBefore = time.Now().Add(-time.Hour).Truncate(time.Second)
+		notAfter  = time.Now().Add(time.Hour).Truncate(time.Second)
+		data      = pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: suite.newCertificate(notBefore, notAfter),
+		})
+	)
+
+	keys, err := suite.newParser().Parse(SuffixPEM, append(data, singlePEM...))
+	suite.Require().NoError(err)
+	suite.Require().Len(keys, 2)
+
+	suite.Equal(string(jwa.EC), keys[0].KeyType())
+	suite.IsType((*ecdsa.PublicKey)(nil), keys[0].Public())
+	suite.True(notBefore.Equal(keys[0].NotBefore()))
+	suite.True(notAfter.Equal(keys[0].ExpiresAt()))
+	suite.Require().Len(keys[0].Certificates(), 1)
+	suite.NotEmpty(keys[0].CertificateThumbprint())
+	suite.NotEmpty(keys[0].CertificateThumbprintS256())
+	suite.Equal(
+		Keys{keys[0]},
+		Keys(keys).Filter(ByCertificateThumbprintS256(keys[0].CertificateThumbprintS256())),
+	)
+
+	// a plain key has no lifetime
+	suite.assertRSAKey(keys[1])
+	suite.True(keys[1].NotBefore().IsZero())
+	suite.True(keys[1].ExpiresAt().IsZero())
+}
+
+func (suite *ParserSuite) TestPEMNoBlocks() {
+	keys, err := suite.newParser().Parse(SuffixPEM, []byte("this is not PEM"))
+	suite.Empty(keys)
+	suite.ErrorIs(err, ErrNoPEMBlocks)
+}
+
 func TestParser(t *testing.T) {
 	suite.Run(t, new(ParserSuite))
 }
@@ -6,8 +6,11 @@ package clortho
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/xmidt-org/chronon"
 	"go.uber.org/multierr"
@@ -19,8 +22,31 @@ var (
 
 	// ErrRefresherStopped is returned by Refresher.Stop if the Refresher is not running.
 	ErrRefresherStopped = errors.New("That refresher is not running")
+
+	// ErrRefreshSourceNotFound is returned by Refresher.Refresh if no refresh source has the given URI.
+	ErrRefreshSourceNotFound = errors.New("No refresh source exists with that URI")
 )
 
+// InitialSyncError is returned by Refresher.Start when one or more required sources
+// did not successfully produce keys before the Start context expired.
+type InitialSyncError struct {
+	// URIs are the required sources that failed to sync, sorted lexicographically.
+	URIs []string
+
+	// Err holds the most recent error for each failed source along with the
+	// context error that ended the wait.
+	Err error
+}
+
+func (ise *InitialSyncError) Error() string {
+	return fmt.Sprintf("Required refresh sources failed to sync [%s]: %s", strings.Join(ise.URIs, ", "), ise.Err)
+}
+
+// Unwrap returns the underlying error(s).
+func (ise *InitialSyncError) Unwrap() error {
+	return ise.Err
+}
+
 // RefreshEvent represents a set of keys from a given URI that has been
 // asynchronously fetched.
 type RefreshEvent struct {
@@ -51,6 +77,10 @@ type RefreshEvent struct {
 	//
 	// This field will be sorted by KeyID.
 	Deleted Keys
+
+	// Meta describes the content that produced the Keys field.  When Err is not nil,
+	// this field will be the metadata from the last successful fetch, if any.
+	Meta ContentMeta
 }
 
 // RefreshListener is a sink for RefreshEvents.
@@ -64,6 +94,13 @@ type Refresher interface {
 	// Start bootstraps tasks that fetch keys and dispatch events to any listeners.
 	// Keys will arrive asynchronously to any registered listeners.
 	//
+	// If any sources are required, this method blocks until each required source has
+	// dispatched a successful event or until the given context expires.  In the latter case,
+	// all tasks are shut down and an *InitialSyncError is returned.  While this method
+	// waits, this Refresher reports itself as running, and it can be stopped or have its
+	// sources changed.  If Stop is called before the required sources have synced, this
+	// method returns ErrRefresherStopped.
+	//
 	// If this Refresher has already been started, this method returns ErrRefresherStarted.
 	Start(context.Context) error
 
@@ -80,6 +117,54 @@ type Refresher interface {
 	// are not required to use this closure, particularly if the listener is active for the
 	// life of the application.
 	AddListener(l RefreshListener) CancelListenerFunc
+
+	// Refresh triggers an immediate refresh of the source with the given URI and returns
+	// the resulting event, which is also dispatched to any listeners.  Concurrent triggers
+	// for the same source are coalesced into a single fetch.
+	//
+	// Unless force is true, a source is not refreshed more often than its MinInterval.  In
+	// that case, no fetch is done and the most recent event for the source is returned.
+	//
+	// If this Refresher is not running, or if it is stopped while waiting on the refresh,
+	// this method returns ErrRefresherStopped.  If no source has the given URI, this method
+	// returns ErrRefreshSourceNotFound.  If the context expires before the refresh completes,
+	// the context's error is returned.
+	Refresh(ctx context.Context, uri string, force bool) (RefreshEvent, error)
+
+	// RefreshAll triggers an immediate refresh of every source, as with Refresh, and returns
+	// the resulting events in the same order as the sources were configured.
+	//
+	// If an error prevents any refresh from completing, that error is returned along with
+	// the events that did complete.
+	RefreshAll(ctx context.Context, force bool) ([]RefreshEvent, error)
+
+	// AddSource adds a refresh source.  If this Refresher is running, a task is
+	// started immediately for the new source.  Unlike Start, this method never waits
+	// for the source to sync, even if it is required.
+	//
+	// The source is validated in the same way as NewRefresher, including rejecting
+	// a URI that duplicates an existing source.
+	AddSource(RefreshSource) error
+
+	// RemoveSource removes the refresh source with the given URI, stopping its task
+	// if this Refresher is running.  A final event is dispatched with the source's
+	// last known keys in the Deleted field.
+	//
+	// If no source has the given URI, this method returns ErrRefreshSourceNotFound.
+	RemoveSource(uri string) error
+
+	// UpdateSource replaces the configuration of the existing source with the same URI.
+	// If this Refresher is running, the source's task is restarted with the new
+	// configuration, which immediately fetches keys again.  Events from the restarted
+	// task report New and Deleted keys relative to the keys already dispatched.
+	//
+	// If no source has the same URI, this method returns ErrRefreshSourceNotFound.
+	UpdateSource(RefreshSource) error
+
+	// Status returns a snapshot of this Refresher's state, including the state of
+	// each refresh source.  This method may be called whether or not this Refresher
+	// is running.
+	Status() RefresherStatus
 }
 
 // NewRefresher constructs a Refresher using the supplied options.  Without any options,
@@ -101,6 +186,11 @@ func NewRefresher(options ...RefresherOption) (Refresher, error) {
 	err = multierr.Append(err, validateRefreshSources(r.sources...))
 	if err != nil {
 		r = nil
+	} else {
+		r.statuses = make([]*sourceStatus, 0, len(r.sources))
+		for _, s := range r.sources {
+			r.statuses = append(r.statuses, r.newSourceStatus(s))
+		}
 	}
 
 	return r, err
@@ -108,46 +198,141 @@ func NewRefresher(options ...RefresherOption) (Refresher, error) {
 
 // refresher is the internal Refresher implementation.
 type refresher struct {
-	fetcher   Fetcher
-	sources   []RefreshSource
-	listeners listeners
+	fetcher    Fetcher
+	requireAll bool
+	listeners  listeners
 
 	clock chronon.Clock
 
-	taskLock   sync.Mutex
-	taskCancel context.CancelFunc
-	tasks      []*refreshTask
+	// taskLock guards the sources as well as the tasks.  The sources, statuses,
+	// and tasks slices are parallel.  When this refresher is not running, tasks is nil.
+	//
+	// While Start waits on required sources, starting is that wait's initialSync.
+	// Tasks started during that time use taskDispatch, so that they report to it.
+	taskLock     sync.Mutex
+	sources      []RefreshSource
+	statuses     []*sourceStatus
+	taskCtx      context.Context
+	taskCancel   context.CancelFunc
+	taskDispatch func(RefreshEvent)
+	tasks        []*refreshTask
+	starting     *initialSync
 }
 
-func (r *refresher) Start(_ context.Context) error {
-	r.taskLock.Lock()
-	defer r.taskLock.Unlock()
+// newSourceStatus creates the initial status for a refresh source.
+func (r *refresher) newSourceStatus(s RefreshSource) *sourceStatus {
+	return &sourceStatus{
+		status: SourceStatus{
+			URI:      s.URI,
+			Required: r.requireAll || s.Required,
+		},
+	}
+}
+
+// indexOf returns the index of the source with the given URI, or -1 if no such
+// source exists.  This method must be called under the taskLock.
+func (r *refresher) indexOf(uri string) int {
+	for i, s := range r.sources {
+		if s.URI == uri {
+			return i
+		}
+	}
 
+	return -1
+}
+
+// startTask creates the refreshTask for the source at the given index and starts
+// its goroutine.  The prevKeys, if any, are the keys already dispatched for this
+// source.  This method must be called under the taskLock.
+func (r *refresher) startTask(parent context.Context, i int, dispatch func(RefreshEvent), prevKeys Keys) *refreshTask {
+	ctx, cancel := context.WithCancel(parent)
+	task := &refreshTask{
+		source:   r.sources[i],
+		fetcher:  r.fetcher,
+		jitterer: newJitterer(r.sources[i]),
+		status:   r.statuses[i],
+		prevKeys: prevKeys,
+		dispatch: dispatch,
+		clock:    r.clock,
+		cancel:   cancel,
+		wake:     make(chan struct{}, 1),
+		stopped:  make(chan struct{}),
+	}
+
+	go task.run(ctx)
+	return task
+}
+
+func (r *refresher) Start(ctx context.Context) error {
+	r.taskLock.Lock()
 	if r.taskCancel != nil {
+		r.taskLock.Unlock()
 		return ErrRefresherStarted
 	}
 
-	tasks := make([]*refreshTask, 0, len(r.sources))
-	taskCtx, taskCancel := context.WithCancel(context.Background())
-	for _, s := range r.sources {
-		var (
-			task = &refreshTask{
-				source:   s,
-				fetcher:  r.fetcher,
-				jitterer: newJitterer(s),
-				dispatch: r.dispatch,
-				clock:    r.clock,
-			}
-		)
+	var (
+		is       = newInitialSync(r.requireAll, r.sources)
+		dispatch = r.dispatch
+	)
 
-		go task.run(taskCtx)
-		tasks = append(tasks, task)
+	if is != nil {
+		dispatch = func(event RefreshEvent) {
+			// errors are recorded first, so that they're available if a listener
+			// cancels the Start context.  successes are recorded last, so that
+			// listeners have the keys by the time Start returns.
+			if event.Err != nil {
+				is.onRefreshEvent(event)
+				r.dispatch(event)
+			} else {
+				r.dispatch(event)
+				is.onRefreshEvent(event)
+			}
+		}
 	}
 
+	taskCtx, taskCancel := context.WithCancel(context.Background())
+	r.taskCtx = taskCtx
 	r.taskCancel = taskCancel
-	r.tasks = tasks
+	r.taskDispatch = dispatch
+	r.tasks = make([]*refreshTask, 0, len(r.sources))
+	for i := range r.sources {
+		r.tasks = append(r.tasks, r.startTask(taskCtx, i, dispatch, nil))
+	}
 
-	return nil
+	r.starting = is
+	r.taskLock.Unlock()
+
+	if is == nil {
+		return nil
+	}
+
+	// wait outside the taskLock, so that Stop, Status, etc. aren't blocked
+	// while the required sources sync
+	err := is.wait(ctx, taskCtx.Done())
+
+	r.taskLock.Lock()
+	defer r.taskLock.Unlock()
+
+	// if Stop was called while waiting, possibly followed by another Start,
+	// the tasks started here are no longer this refresher's concern
+	if r.taskCtx == taskCtx {
+		r.starting = nil
+		if err != nil {
+			r.stop()
+		}
+	}
+
+	return err
+}
+
+// stop cancels all tasks.  This method must be called under the taskLock.
+func (r *refresher) stop() {
+	r.taskCancel()
+	r.taskCtx = nil
+	r.taskCancel = nil
+	r.taskDispatch = nil
+	r.tasks = nil
+	r.starting = nil
 }
 
 func (r *refresher) Stop(_ context.Context) error {
@@ -158,9 +343,105 @@ func (r *refresher) Stop(_ context.Context) error {
 		return ErrRefresherStopped
 	}
 
-	r.taskCancel()
-	r.taskCancel = nil
-	r.tasks = nil
+	r.stop()
+	return nil
+}
+
+func (r *refresher) AddSource(s RefreshSource) error {
+	r.taskLock.Lock()
+	defer r.taskLock.Unlock()
+
+	sources := append(append([]RefreshSource(nil), r.sources...), s)
+	if err := validateRefreshSources(sources...); err != nil {
+		return err
+	}
+
+	r.sources = sources
+	r.statuses = append(r.statuses, r.newSourceStatus(s))
+	if r.tasks != nil {
+		r.tasks = append(r.tasks, r.startTask(r.taskCtx, len(r.sources)-1, r.taskDispatch, nil))
+	}
+
+	return nil
+}
+
+func (r *refresher) RemoveSource(uri string) error {
+	r.taskLock.Lock()
+	i := r.indexOf(uri)
+	if i < 0 {
+		r.taskLock.Unlock()
+		return ErrRefreshSourceNotFound
+	}
+
+	status := r.statuses[i]
+	r.sources = append(r.sources[:i:i], r.sources[i+1:]...)
+	r.statuses = append(r.statuses[:i:i], r.statuses[i+1:]...)
+
+	var task *refreshTask
+	if r.tasks != nil {
+		task = r.tasks[i]
+		r.tasks = append(r.tasks[:i:i], r.tasks[i+1:]...)
+		task.cancel()
+	}
+
+	if r.starting != nil {
+		// a removed source can never sync
+		r.starting.forget(uri)
+	}
+
+	r.taskLock.Unlock()
+
+	if task != nil {
+		// wait for any in-flight dispatch, so that the final event is the last one
+		<-task.stopped
+	}
+
+	if keys := status.keys(); len(keys) > 0 {
+		r.dispatch(RefreshEvent{
+			URI:     uri,
+			Deleted: keys,
+		})
+	}
+
+	return nil
+}
+
+func (r *refresher) UpdateSource(s RefreshSource) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	r.taskLock.Lock()
+	i := r.indexOf(s.URI)
+	if i < 0 {
+		r.taskLock.Unlock()
+		return ErrRefreshSourceNotFound
+	}
+
+	var task *refreshTask
+	r.sources[i] = s
+	r.statuses[i].setRequired(r.requireAll || s.Required)
+	if r.tasks != nil {
+		task = r.tasks[i]
+		task.cancel()
+	}
+
+	r.taskLock.Unlock()
+
+	if task == nil {
+		return nil
+	}
+
+	// the replacement task must not start until the old one has dispatched its last event
+	<-task.stopped
+
+	r.taskLock.Lock()
+	defer r.taskLock.Unlock()
+
+	// the source may have been removed, or this refresher stopped, while waiting
+	if i = r.indexOf(s.URI); i >= 0 && r.tasks != nil && r.tasks[i] == task {
+		r.tasks[i] = r.startTask(r.taskCtx, i, r.taskDispatch, r.statuses[i].keys())
+	}
 
 	return nil
 }
@@ -169,19 +450,323 @@ func (r *refresher) AddListener(l RefreshListener) CancelListenerFunc {
 	return r.listeners.addListener(l)
 }
 
+// runningTasks returns the current refresh tasks, or ErrRefresherStopped if
+// this Refresher is not running.
+func (r *refresher) runningTasks() ([]*refreshTask, error) {
+	r.taskLock.Lock()
+	defer r.taskLock.Unlock()
+
+	if r.taskCancel == nil {
+		return nil, ErrRefresherStopped
+	}
+
+	return append([]*refreshTask(nil), r.tasks...), nil
+}
+
+func (r *refresher) Refresh(ctx context.Context, uri string, force bool) (RefreshEvent, error) {
+	tasks, err := r.runningTasks()
+	if err != nil {
+		return RefreshEvent{}, err
+	}
+
+	for _, task := range tasks {
+		if task.source.URI == uri {
+			return task.trigger(ctx, force)
+		}
+	}
+
+	return RefreshEvent{}, ErrRefreshSourceNotFound
+}
+
+func (r *refresher) RefreshAll(ctx context.Context, force bool) ([]RefreshEvent, error) {
+	tasks, err := r.runningTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg     sync.WaitGroup
+		events = make([]RefreshEvent, len(tasks))
+		errs   = make([]error, len(tasks))
+	)
+
+	wg.Add(len(tasks))
+	for i, task := range tasks {
+		go func(i int, task *refreshTask) {
+			defer wg.Done()
+			events[i], errs[i] = task.trigger(ctx, force)
+		}(i, task)
+	}
+
+	wg.Wait()
+
+	// only include the events that completed
+	completed := make([]RefreshEvent, 0, len(events))
+	for i := range events {
+		if errs[i] == nil {
+			completed = append(completed, events[i])
+		} else if err == nil {
+			err = errs[i]
+		}
+	}
+
+	return completed, err
+}
+
+func (r *refresher) Status() (rs RefresherStatus) {
+	r.taskLock.Lock()
+	rs.Running = r.taskCancel != nil
+	statuses := append([]*sourceStatus(nil), r.statuses...)
+	r.taskLock.Unlock()
+
+	rs.Sources = make([]SourceStatus, 0, len(statuses))
+	for _, ss := range statuses {
+		s := ss.snapshot()
+		if !rs.Running {
+			s.NextRefresh = time.Time{}
+		}
+
+		rs.Sources = append(rs.Sources, s)
+	}
+
+	sort.SliceStable(rs.Sources, func(i, j int) bool {
+		return rs.Sources[i].URI < rs.Sources[j].URI
+	})
+
+	return
+}
+
 func (r *refresher) dispatch(event RefreshEvent) {
 	r.listeners.visit(func(l interface{}) {
 		l.(RefreshListener).OnRefreshEvent(event)
 	})
 }
 
+// initialSync tracks the required sources that have yet to produce a successful event.
+type initialSync struct {
+	lock    sync.Mutex
+	pending map[string]error
+	done    chan struct{}
+}
+
+// newInitialSync creates an initialSync for the required sources.  If no
+// sources are required, this function returns nil.
+func newInitialSync(requireAll bool, sources []RefreshSource) *initialSync {
+	pending := make(map[string]error)
+	for _, s := range sources {
+		if requireAll || s.Required {
+			pending[s.URI] = nil
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return &initialSync{
+		pending: pending,
+		done:    make(chan struct{}),
+	}
+}
+
+// onRefreshEvent records the outcome of a refresh.  Events for sources
+// that aren't pending are ignored.
+func (is *initialSync) onRefreshEvent(event RefreshEvent) {
+	is.lock.Lock()
+	defer is.lock.Unlock()
+
+	if _, ok := is.pending[event.URI]; !ok {
+		return
+	}
+
+	if event.Err != nil {
+		is.pending[event.URI] = event.Err
+		return
+	}
+
+	is.remove(event.URI)
+}
+
+// forget stops waiting on the given source, e.g. because it was removed.
+func (is *initialSync) forget(uri string) {
+	is.lock.Lock()
+	defer is.lock.Unlock()
+	is.remove(uri)
+}
+
+// remove deletes a pending source, signaling done if it was the last one.
+// This method must be called under the lock.
+func (is *initialSync) remove(uri string) {
+	if _, ok := is.pending[uri]; !ok {
+		return
+	}
+
+	delete(is.pending, uri)
+	if len(is.pending) == 0 {
+		close(is.done)
+	}
+}
+
+// wait blocks until all required sources have synced, the context expires,
+// or the stopped channel is closed.  In the last case, ErrRefresherStopped is returned.
+func (is *initialSync) wait(ctx context.Context, stopped <-chan struct{}) error {
+	select {
+	case <-is.done:
+		return nil
+
+	case <-stopped:
+		return ErrRefresherStopped
+
+	case <-ctx.Done():
+	}
+
+	is.lock.Lock()
+	defer is.lock.Unlock()
+
+	if len(is.pending) == 0 {
+		// the last source synced just as the context expired
+		return nil
+	}
+
+	var (
+		uris = make([]string, 0, len(is.pending))
+		err  error
+	)
+
+	for uri := range is.pending {
+		uris = append(uris, uri)
+	}
+
+	sort.Strings(uris)
+	for _, uri := range uris {
+		if pendingErr := is.pending[uri]; pendingErr != nil {
+			err = multierr.Append(err, fmt.Errorf("%s: %w", uri, pendingErr))
+		}
+	}
+
+	return &InitialSyncError{
+		URIs: uris,
+		Err:  multierr.Append(err, ctx.Err()),
+	}
+}
+
+// refreshRequest is an on-demand refresh that one or more callers are waiting on.
+type refreshRequest struct {
+	force bool
+	done  chan struct{}
+	event RefreshEvent
+}
+
+// complete delivers the event to any callers waiting on this request.  This method
+// is a nop if the request is nil.
+func (rr *refreshRequest) complete(event RefreshEvent) {
+	if rr != nil {
+		rr.event = event
+		close(rr.done)
+	}
+}
+
 type refreshTask struct {
 	source   RefreshSource
 	fetcher  Fetcher
 	jitterer jitterer
+	status   *sourceStatus
+
+	// prevKeys are the keys already dispatched for this source, e.g. by the task
+	// this one replaced.  This field is only read when the task starts.
+	prevKeys Keys
 
 	dispatch func(RefreshEvent)
 	clock    chronon.Clock
+
+	// cancel shuts down this task
+	cancel context.CancelFunc
+
+	// wake signals the run goroutine that a refresh has been requested
+	wake chan struct{}
+
+	// stopped is closed when the run goroutine exits
+	stopped chan struct{}
+
+	requestLock sync.Mutex
+	request     *refreshRequest
+}
+
+// trigger requests an immediate refresh and waits for the result.  If a request
+// is already pending, this method joins it.
+func (rt *refreshTask) trigger(ctx context.Context, force bool) (RefreshEvent, error) {
+	rt.requestLock.Lock()
+	request := rt.request
+	if request == nil {
+		request = &refreshRequest{
+			done: make(chan struct{}),
+		}
+
+		rt.request = request
+	}
+
+	request.force = request.force || force
+	rt.requestLock.Unlock()
+
+	select {
+	case rt.wake <- struct{}{}:
+	default:
+		// the run goroutine has already been signaled
+	}
+
+	select {
+	case <-request.done:
+		return request.event, nil
+
+	case <-rt.stopped:
+		return RefreshEvent{}, ErrRefresherStopped
+
+	case <-ctx.Done():
+		return RefreshEvent{}, ctx.Err()
+	}
+}
+
+// takeRequest removes and returns the pending request, if any.
+func (rt *refreshTask) takeRequest() (request *refreshRequest) {
+	rt.requestLock.Lock()
+	request, rt.request = rt.request, nil
+	rt.requestLock.Unlock()
+	return
+}
+
+// wait blocks until the next refresh is due, either because the given interval has
+// elapsed or because a refresh was requested.  Requests that aren't forced and that
+// arrive before the MinInterval has elapsed since lastAttempt are completed with the
+// last event without waking the task.
+//
+// If the context is canceled, this method returns false.  Otherwise, the returned
+// request will be non-nil if the refresh was requested.
+func (rt *refreshTask) wait(ctx context.Context, next time.Duration, lastAttempt time.Time, lastEvent RefreshEvent) (*refreshRequest, bool) {
+	timer := rt.clock.NewTimer(next)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+
+		case <-timer.C():
+			return nil, true
+
+		case <-rt.wake:
+			request := rt.takeRequest()
+			switch {
+			case request == nil:
+				// another wake already took the request
+
+			case !request.force && rt.clock.Now().Sub(lastAttempt) < rt.jitterer.minInterval:
+				request.complete(lastEvent)
+
+			default:
+				return request, true
+			}
+		}
+	}
 }
 
 func (rt *refreshTask) newKeyMap(keys []Key) (m map[string]Key) {
@@ -213,12 +798,25 @@ func (rt *refreshTask) findChanges(next, prev map[string]Key) (newKeys, deletedK
 
 func (rt *refreshTask) run(ctx context.Context) {
 	var (
-		prevKeys   []Key
-		prevKeyMap map[string]Key
+		prevKeys   = []Key(rt.prevKeys)
+		prevKeyMap = rt.newKeyMap(prevKeys)
 		prevMeta   ContentMeta
+
+		// lastMeta is the metadata from the last successful fetch.  Unlike prevMeta,
+		// this isn't reset on errors, so that any stale-if-error window can be honored.
+		lastMeta ContentMeta
+
+		// retries is the number of consecutive errors, which drives the backoff policy
+		retries int
+
+		// request is the on-demand refresh, if any, that caused the current fetch
+		request *refreshRequest
 	)
 
+	defer close(rt.stopped)
 	for {
+		lastAttempt := rt.clock.Now()
+		rt.status.onAttempt(lastAttempt)
 		nextKeys, nextMeta, err := rt.fetcher.Fetch(ctx, rt.source.URI, prevMeta)
 		event := RefreshEvent{
 			URI: rt.source.URI,
@@ -242,10 +840,25 @@ func (rt *refreshTask) run(ctx context.Context) {
 			prevKeys = nextKeys
 			prevKeyMap = nextKeyMap
 			prevMeta = nextMeta
+			lastMeta = nextMeta
+			retries = 0
+
+		case errors.Is(err, ErrNotModified):
+			// the source hasn't changed, so reuse the previous keys without
+			// reparsing anything.  this isn't an error from a listener's point of view.
+			event.Err = nil
+			err = nil
+			prevMeta = nextMeta
+			lastMeta = nextMeta
+			retries = 0
+
+			event.Keys = make([]Key, len(prevKeys))
+			copy(event.Keys, prevKeys)
 
 		case err != nil:
 			// reset the content metadata
 			prevMeta = ContentMeta{}
+			retries++
 
 			// send out the previous keys, and leave New/Deleted unset
 			event.Keys = make([]Key, len(prevKeys))
@@ -255,20 +868,25 @@ func (rt *refreshTask) run(ctx context.Context) {
 		sort.Sort(event.Keys)
 		sort.Sort(event.New)
 		sort.Sort(event.Deleted)
-		rt.dispatch(event)
+		event.Meta = lastMeta
 
-		var (
-			next  = rt.jitterer.nextInterval(prevMeta, err)
-			timer = rt.clock.NewTimer(next)
-		)
+		var next time.Duration
+		if err != nil {
+			next = rt.jitterer.nextRetry(lastMeta, err, retries)
+		} else {
+			next = rt.jitterer.nextInterval(lastMeta, err)
+		}
 
-		select {
-		case <-ctx.Done():
-			timer.Stop()
-			return
+		// the status is updated before dispatching, so that listeners see
+		// a status consistent with the event
+		now := rt.clock.Now()
+		rt.status.onResult(now, event, lastMeta, now.Add(next))
+		rt.dispatch(event)
+		request.complete(event)
 
-		case <-timer.C():
-			// just wait to restart the loop
+		var ok bool
+		if request, ok = rt.wait(ctx, next, lastAttempt, event); !ok {
+			return
 		}
 	}
 }
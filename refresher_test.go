@@ -165,6 +165,7 @@ func (suite *RefresherSuite) testRefresh(source RefreshSource) {
 		URI:  source.URI,
 		Keys: suite.set1,
 		New:  suite.set1, // this is the first event, so everything's new
+		Meta: ContentMeta{Format: MediaTypeJWKSet},
 	}).Once()
 
 	f.ExpectFetchCtx(matchContext, source.URI, ContentMeta{Format: MediaTypeJWKSet}).
@@ -174,6 +175,7 @@ func (suite *RefresherSuite) testRefresh(source RefreshSource) {
 		URI:  source.URI,
 		Keys: suite.set1, // the previous keys should be sent on error
 		Err:  expectedError,
+		Meta: ContentMeta{Format: MediaTypeJWKSet}, // as well as the last successful metadata
 	}).Once()
 
 	f.ExpectFetchCtx(matchContext, source.URI, ContentMeta{}).
@@ -234,6 +236,163 @@ func (suite *RefresherSuite) TestRefresh() {
 	})
 }
 
+func (suite *RefresherSuite) TestNotModified() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(RefreshSource{URI: "http://getkeys.com/keys"}),
+		)
+
+		listener     = new(mockRefreshListener)
+		fc           = suite.newClockFor(r)
+		timerCh      = make(chan chronon.FakeTimer, 1)
+		firstMeta    = ContentMeta{Format: MediaTypeJWKSet, ETag: `"v1"`}
+		secondMeta   = ContentMeta{Format: MediaTypeJWKSet, ETag: `"v1"`, TTL: time.Hour}
+		matchContext = func(ctx context.Context) bool {
+			return suite.NotEqual(context.Background(), ctx)
+		}
+	)
+
+	r.AddListener(listener)
+	fc.NotifyOnTimer(timerCh)
+
+	f.ExpectFetchCtx(matchContext, "http://getkeys.com/keys", ContentMeta{}).
+		Return(suite.set1, firstMeta, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Keys: suite.set1,
+		New:  suite.set1,
+		Meta: firstMeta,
+	}).Once()
+
+	// the previous keys are reused, and no error is reported
+	f.ExpectFetchCtx(matchContext, "http://getkeys.com/keys", firstMeta).
+		Return([]Key(nil), secondMeta, ErrNotModified).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Keys: suite.set1,
+		Meta: secondMeta,
+	}).Once()
+
+	// the updated metadata from the not modified response should be used
+	f.ExpectFetchCtx(matchContext, "http://getkeys.com/keys", secondMeta).
+		Return(suite.set1, firstMeta, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Keys: suite.set1,
+		Meta: firstMeta,
+	}).Once()
+
+	suite.Require().NoError(
+		r.Start(context.Background()),
+	)
+
+	timer := suite.getTimer(timerCh)
+	for i := 0; i < 2; i++ {
+		fc.Set(timer.When())
+		timer = suite.getTimer(timerCh)
+	}
+
+	suite.NoError(
+		r.Stop(context.Background()),
+	)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *RefresherSuite) TestBackoff() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(RefreshSource{
+				URI: "http://getkeys.com/keys",
+				Backoff: RefreshBackoff{
+					InitialDelay: 10 * time.Second,
+					Multiplier:   3.0,
+				},
+			}),
+		)
+
+		listener     = new(mockRefreshListener)
+		fc           = suite.newClockFor(r)
+		timerCh      = make(chan chronon.FakeTimer, 1)
+		meta         = ContentMeta{Format: MediaTypeJWKSet}
+		fetchErr     = errors.New("expected")
+		matchContext = func(ctx context.Context) bool {
+			return suite.NotEqual(context.Background(), ctx)
+		}
+	)
+
+	r.AddListener(listener)
+	fc.NotifyOnTimer(timerCh)
+
+	f.ExpectFetchCtx(matchContext, "http://getkeys.com/keys", ContentMeta{}).
+		Return([]Key(nil), ContentMeta{}, fetchErr).
+		Twice()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Err:  fetchErr,
+		Keys: Keys{},
+	}).Twice()
+
+	f.ExpectFetchCtx(matchContext, "http://getkeys.com/keys", ContentMeta{}).
+		Return(suite.set1, meta, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Keys: suite.set1,
+		New:  suite.set1,
+		Meta: meta,
+	}).Once()
+
+	// the backoff should have been reset by the successful fetch
+	f.ExpectFetchCtx(matchContext, "http://getkeys.com/keys", meta).
+		Return([]Key(nil), ContentMeta{}, fetchErr).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Keys: suite.set1,
+		Err:  fetchErr,
+		Meta: meta,
+	}).Once()
+
+	suite.Require().NoError(
+		r.Start(context.Background()),
+	)
+
+	expected := []struct {
+		lo, hi time.Duration
+	}{
+		{lo: 9 * time.Second, hi: 11 * time.Second},
+		{lo: 27 * time.Second, hi: 33 * time.Second},
+		{lo: DefaultRefreshMinInterval, hi: time.Duration(float64(DefaultRefreshInterval) * (1.0 + DefaultRefreshJitter))},
+		{lo: 9 * time.Second, hi: 11 * time.Second},
+	}
+
+	for i, e := range expected {
+		timer := suite.getTimer(timerCh)
+		next := timer.When().Sub(fc.Now())
+		suite.GreaterOrEqual(next, e.lo, "next is too low")
+		suite.GreaterOrEqual(e.hi, next, "next is too high")
+		if i < len(expected)-1 {
+			fc.Set(timer.When())
+		}
+	}
+
+	suite.NoError(
+		r.Stop(context.Background()),
+	)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
 func (suite *RefresherSuite) TestStopDuringFetch() {
 	var (
 		f = new(mockFetcher)
@@ -279,6 +438,472 @@ func (suite *RefresherSuite) TestStopDuringFetch() {
 	listener.AssertExpectations(suite.T())
 }
 
+func (suite *RefresherSuite) testRequiredSynced(options ...RefresherOption) {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			append(options, WithFetcher(f))...,
+		)
+
+		listener     = new(mockRefreshListener)
+		matchContext = func(ctx context.Context) bool {
+			return suite.NotEqual(context.Background(), ctx)
+		}
+	)
+
+	suite.newClockFor(r)
+	r.AddListener(listener)
+	for _, uri := range []string{"http://getkeys.com/keys1", "http://getkeys.com/keys2"} {
+		f.ExpectFetchCtx(matchContext, uri, ContentMeta{}).
+			Return(suite.set1, ContentMeta{Format: MediaTypeJWKSet}, error(nil)).
+			Once()
+		listener.ExpectOnRefreshEvent(RefreshEvent{
+			URI:  uri,
+			Keys: suite.set1,
+			New:  suite.set1,
+			Meta: ContentMeta{Format: MediaTypeJWKSet},
+		}).Once()
+	}
+
+	suite.Require().NoError(
+		r.Start(context.Background()),
+	)
+
+	// all required sources must have been dispatched by the time Start returns
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+
+	suite.NoError(
+		r.Stop(context.Background()),
+	)
+}
+
+func (suite *RefresherSuite) testRequiredFailed() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(
+				RefreshSource{URI: "http://getkeys.com/keys", Required: true},
+			),
+		)
+
+		listener         = new(mockRefreshListener)
+		fetchErr         = errors.New("expected")
+		startCtx, cancel = context.WithCancel(context.Background())
+		matchContext     = func(ctx context.Context) bool {
+			return suite.NotEqual(context.Background(), ctx)
+		}
+	)
+
+	defer cancel()
+	suite.newClockFor(r)
+	r.AddListener(listener)
+	f.ExpectFetchCtx(matchContext, "http://getkeys.com/keys", ContentMeta{}).
+		Return([]Key(nil), ContentMeta{}, fetchErr).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  "http://getkeys.com/keys",
+		Err:  fetchErr,
+		Keys: Keys{},
+	}).
+		Run(func(mock.Arguments) {
+			cancel()
+		}).
+		Once()
+
+	err := r.Start(startCtx)
+	suite.Require().Error(err)
+
+	var ise *InitialSyncError
+	suite.Require().ErrorAs(err, &ise)
+	suite.Equal([]string{"http://getkeys.com/keys"}, ise.URIs)
+	suite.ErrorIs(err, fetchErr)
+	suite.ErrorIs(err, context.Canceled)
+	suite.Contains(err.Error(), "http://getkeys.com/keys")
+
+	// a failed start leaves the refresher stopped
+	suite.ErrorIs(r.Stop(context.Background()), ErrRefresherStopped)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+// startBlocked calls Start in a goroutine with a required source whose fetch blocks
+// until its task is canceled.  The returned channel receives Start's result.
+func (suite *RefresherSuite) startBlocked(f *mockFetcher, r Refresher) <-chan error {
+	var (
+		fetchReady = make(chan struct{})
+		startErr   = make(chan error, 1)
+	)
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/keys", ContentMeta{}).
+		Return([]Key(nil), ContentMeta{}, context.Canceled).
+		Run(func(args mock.Arguments) {
+			close(fetchReady)
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Once()
+
+	go func() {
+		startErr <- r.Start(context.Background())
+	}()
+
+	select {
+	case <-time.After(2 * time.Second):
+		suite.FailNow("Fetch was not called")
+	case <-fetchReady:
+		// passing
+	}
+
+	return startErr
+}
+
+func (suite *RefresherSuite) testRequiredStopped() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(
+				RefreshSource{URI: "http://getkeys.com/keys", Required: true},
+			),
+		)
+	)
+
+	suite.newClockFor(r)
+	startErr := suite.startBlocked(f, r)
+
+	// neither Status nor Stop can block on a pending Start
+	status := r.Status()
+	suite.True(status.Running)
+	suite.False(status.Ready())
+	suite.ErrorIs(r.Start(context.Background()), ErrRefresherStarted)
+	suite.NoError(r.Stop(context.Background()))
+
+	select {
+	case <-time.After(2 * time.Second):
+		suite.Fail("Start did not return")
+	case err := <-startErr:
+		suite.ErrorIs(err, ErrRefresherStopped)
+	}
+
+	suite.False(r.Status().Running)
+	suite.ErrorIs(r.Stop(context.Background()), ErrRefresherStopped)
+}
+
+func (suite *RefresherSuite) testRequiredRemoved() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(
+				RefreshSource{URI: "http://getkeys.com/keys", Required: true},
+			),
+		)
+	)
+
+	suite.newClockFor(r)
+	startErr := suite.startBlocked(f, r)
+
+	// once the only required source is gone, there's nothing left to wait on
+	suite.NoError(r.RemoveSource("http://getkeys.com/keys"))
+
+	select {
+	case <-time.After(2 * time.Second):
+		suite.Fail("Start did not return")
+	case err := <-startErr:
+		suite.NoError(err)
+	}
+
+	suite.True(r.Status().Running)
+	suite.NoError(r.Stop(context.Background()))
+}
+
+func (suite *RefresherSuite) TestRequired() {
+	suite.Run("Source", func() {
+		suite.testRequiredSynced(
+			WithSources(
+				RefreshSource{URI: "http://getkeys.com/keys1", Required: true},
+				RefreshSource{URI: "http://getkeys.com/keys2", Required: true},
+			),
+		)
+	})
+
+	suite.Run("RequireAllSources", func() {
+		suite.testRequiredSynced(
+			WithSources(
+				RefreshSource{URI: "http://getkeys.com/keys1"},
+				RefreshSource{URI: "http://getkeys.com/keys2"},
+			),
+			WithRequireAllSources(),
+		)
+	})
+
+	suite.Run("Config", func() {
+		suite.testRequiredSynced(
+			WithConfig(Config{
+				Refresh: RefreshConfig{
+					Sources: []RefreshSource{
+						{URI: "http://getkeys.com/keys1"},
+						{URI: "http://getkeys.com/keys2"},
+					},
+					RequireAllSources: true,
+				},
+			}),
+		)
+	})
+
+	suite.Run("Failed", suite.testRequiredFailed)
+	suite.Run("Stopped", suite.testRequiredStopped)
+	suite.Run("Removed", suite.testRequiredRemoved)
+}
+
+func (suite *RefresherSuite) TestRefreshOnDemand() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(RefreshSource{URI: "http://getkeys.com/keys"}),
+		)
+
+		listener = new(mockRefreshListener)
+		fc       = suite.newClockFor(r)
+		timerCh  = make(chan chronon.FakeTimer, 1)
+
+		firstEvent = RefreshEvent{
+			URI:  "http://getkeys.com/keys",
+			Keys: suite.set1,
+			New:  suite.set1,
+		}
+
+		secondEvent = RefreshEvent{
+			URI:     "http://getkeys.com/keys",
+			Keys:    suite.set2,
+			New:     []Key{suite.set2[2]}, // added kid "D"
+			Deleted: []Key{suite.set1[1]}, // deleted kid "B"
+		}
+	)
+
+	r.AddListener(listener)
+	fc.NotifyOnTimer(timerCh)
+
+	_, err := r.Refresh(context.Background(), "http://getkeys.com/keys", true)
+	suite.ErrorIs(err, ErrRefresherStopped)
+
+	_, err = r.RefreshAll(context.Background(), true)
+	suite.ErrorIs(err, ErrRefresherStopped)
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/keys", ContentMeta{}).
+		Return(suite.set1, ContentMeta{}, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(firstEvent).Once()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/keys", ContentMeta{}).
+		Return(suite.set2, ContentMeta{}, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(secondEvent).Once()
+
+	suite.Require().NoError(
+		r.Start(context.Background()),
+	)
+
+	suite.getTimer(timerCh)
+
+	_, err = r.Refresh(context.Background(), "http://nosuch.com/keys", true)
+	suite.ErrorIs(err, ErrRefreshSourceNotFound)
+
+	// the MinInterval hasn't elapsed, so no fetch should happen
+	event, err := r.Refresh(context.Background(), "http://getkeys.com/keys", false)
+	suite.NoError(err)
+	suite.Equal(firstEvent, event)
+
+	// forcing the refresh ignores the MinInterval
+	event, err = r.Refresh(context.Background(), "http://getkeys.com/keys", true)
+	suite.NoError(err)
+	suite.Equal(secondEvent, event)
+	suite.getTimer(timerCh)
+
+	events, err := r.RefreshAll(context.Background(), false)
+	suite.NoError(err)
+	suite.Equal([]RefreshEvent{secondEvent}, events)
+
+	suite.NoError(
+		r.Stop(context.Background()),
+	)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *RefresherSuite) TestDynamicSources() {
+	const (
+		uri1 = "http://getkeys.com/keys"
+		uri2 = "http://another.com/keys"
+	)
+
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(RefreshSource{URI: uri1}),
+		)
+
+		listener = new(mockRefreshListener)
+		fc       = suite.newClockFor(r)
+		timerCh  = make(chan chronon.FakeTimer, 1)
+	)
+
+	r.AddListener(listener)
+	fc.NotifyOnTimer(timerCh)
+
+	f.ExpectFetch(mock.Anything, uri1, ContentMeta{}).
+		Return(suite.set1, ContentMeta{}, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  uri1,
+		Keys: suite.set1,
+		New:  suite.set1,
+	}).Once()
+
+	f.ExpectFetch(mock.Anything, uri2, ContentMeta{}).
+		Return(suite.set2, ContentMeta{}, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:  uri2,
+		Keys: suite.set2,
+		New:  suite.set2,
+	}).Once()
+
+	// the updated source reports changes relative to the keys already dispatched
+	f.ExpectFetch(mock.Anything, uri1, ContentMeta{}).
+		Return(suite.set2, ContentMeta{}, error(nil)).
+		Once()
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:     uri1,
+		Keys:    suite.set2,
+		New:     []Key{suite.set2[2]}, // added kid "D"
+		Deleted: []Key{suite.set1[1]}, // deleted kid "B"
+	}).Once()
+
+	listener.ExpectOnRefreshEvent(RefreshEvent{
+		URI:     uri2,
+		Deleted: suite.set2,
+	}).Once()
+
+	suite.Require().NoError(
+		r.Start(context.Background()),
+	)
+
+	suite.getTimer(timerCh)
+
+	suite.Error(r.AddSource(RefreshSource{URI: uri1}))
+	suite.Error(r.AddSource(RefreshSource{}))
+
+	suite.Require().NoError(r.AddSource(RefreshSource{URI: uri2}))
+	suite.getTimer(timerCh)
+	suite.Len(r.Status().Sources, 2)
+
+	suite.ErrorIs(
+		r.UpdateSource(RefreshSource{URI: "http://nosuch.com/keys"}),
+		ErrRefreshSourceNotFound,
+	)
+
+	suite.Require().NoError(r.UpdateSource(RefreshSource{URI: uri1, Interval: time.Hour}))
+	suite.getTimer(timerCh)
+
+	suite.Require().NoError(r.RemoveSource(uri2))
+	suite.ErrorIs(r.RemoveSource(uri2), ErrRefreshSourceNotFound)
+
+	status := r.Status()
+	suite.Require().Len(status.Sources, 1)
+	suite.Equal(uri1, status.Sources[0].URI)
+
+	suite.NoError(
+		r.Stop(context.Background()),
+	)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *RefresherSuite) TestStatus() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newRefresher(
+			WithFetcher(f),
+			WithSources(
+				RefreshSource{URI: "http://getkeys.com/keys", Required: true},
+				RefreshSource{URI: "http://another.com/keys"},
+			),
+		)
+
+		expectedError = errors.New("expected")
+		fc            = suite.newClockFor(r)
+		timerCh       = make(chan chronon.FakeTimer, 2)
+		meta          = ContentMeta{Format: MediaTypeJWKSet, ETag: `"v1"`}
+	)
+
+	fc.NotifyOnTimer(timerCh)
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/keys", ContentMeta{}).
+		Return(suite.set1, meta, error(nil)).
+		Once()
+	f.ExpectFetch(mock.Anything, "http://another.com/keys", ContentMeta{}).
+		Return([]Key(nil), ContentMeta{}, expectedError).
+		Once()
+
+	status := r.Status()
+	suite.False(status.Running)
+	suite.False(status.Ready())
+	suite.Require().Len(status.Sources, 2)
+	suite.Equal("http://another.com/keys", status.Sources[0].URI)
+	suite.False(status.Sources[0].Required)
+	suite.Equal("http://getkeys.com/keys", status.Sources[1].URI)
+	suite.True(status.Sources[1].Required)
+
+	suite.Require().NoError(
+		r.Start(context.Background()),
+	)
+
+	// wait for both tasks to schedule their next refresh
+	suite.getTimer(timerCh)
+	suite.getTimer(timerCh)
+
+	status = r.Status()
+	suite.True(status.Running)
+	suite.True(status.Ready())
+	suite.Require().Len(status.Sources, 2)
+
+	failed := status.Sources[0]
+	suite.Equal(fc.Now(), failed.LastAttempt)
+	suite.True(failed.LastSuccess.IsZero())
+	suite.False(failed.Synced())
+	suite.ErrorIs(failed.LastError, expectedError)
+	suite.Empty(failed.KeyIDs)
+	suite.True(failed.NextRefresh.After(fc.Now()))
+
+	synced := status.Sources[1]
+	suite.Equal(fc.Now(), synced.LastAttempt)
+	suite.Equal(fc.Now(), synced.LastSuccess)
+	suite.True(synced.Synced())
+	suite.NoError(synced.LastError)
+	suite.Equal([]string{"A", "B", "C"}, synced.KeyIDs)
+	suite.Equal(meta, synced.Meta)
+	suite.True(synced.NextRefresh.After(fc.Now()))
+
+	suite.NoError(
+		r.Stop(context.Background()),
+	)
+
+	status = r.Status()
+	suite.False(status.Running)
+	suite.False(status.Ready())
+	suite.Require().Len(status.Sources, 2)
+	suite.True(status.Sources[1].Synced())
+	suite.True(status.Sources[1].NextRefresh.IsZero())
+
+	f.AssertExpectations(suite.T())
+}
+
 func (suite *RefresherSuite) TestMissingURI() {
 	r, err := NewRefresher(
 		WithSources(RefreshSource{}),
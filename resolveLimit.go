@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a concurrent-safe token bucket rate limiter.
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full tokenBucket.  A burst less than 1 is treated as 1.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// reserve takes a token from this bucket, returning how long the caller must wait
+// before using it.  If that wait would exceed maxWait, no token is taken and this
+// method returns false.
+func (tb *tokenBucket) reserve(now time.Time, maxWait time.Duration) (delay time.Duration, ok bool) {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+
+	if !tb.last.IsZero() {
+		if elapsed := now.Sub(tb.last); elapsed > 0 {
+			tb.tokens = min(tb.burst, tb.tokens+elapsed.Seconds()*tb.rate)
+		}
+	}
+
+	tb.last = now
+	tokens := tb.tokens - 1
+	if tokens < 0 {
+		delay = time.Duration(-tokens / tb.rate * float64(time.Second))
+	}
+
+	if ok = delay <= maxWait; ok {
+		tb.tokens = tokens
+	}
+
+	return
+}
+
+// wait blocks for the given duration using this resolver's clock.
+func (r *resolver) wait(d time.Duration) {
+	if d > 0 {
+		timer := r.clock.NewTimer(d)
+		defer timer.Stop()
+		<-timer.C()
+	}
+}
+
+// acquire waits for this resolver's rate limit and concurrency cap, if configured.
+// No more than maxQueueWait is spent waiting.  The returned closure must be called
+// once the fetch is complete.
+func (r *resolver) acquire() (release func(), err error) {
+	start := r.clock.Now()
+	if r.bucket != nil {
+		delay, ok := r.bucket.reserve(start, r.maxQueueWait)
+		if !ok {
+			return nil, fmt.Errorf("%w: the rate limit has been exceeded", ErrResolveRateLimited)
+		}
+
+		r.wait(delay)
+	}
+
+	if r.slots == nil {
+		return nopCancel, nil
+	}
+
+	release = func() { <-r.slots }
+	select {
+	case r.slots <- struct{}{}:
+		return
+
+	default:
+		// all slots are taken, so fall through and wait
+	}
+
+	if remaining := r.maxQueueWait - r.clock.Now().Sub(start); remaining > 0 {
+		timer := r.clock.NewTimer(remaining)
+		defer timer.Stop()
+
+		select {
+		case r.slots <- struct{}{}:
+			return
+
+		case <-timer.C():
+		}
+	}
+
+	return nil, fmt.Errorf("%w: the maximum number of concurrent resolves has been reached", ErrResolveRateLimited)
+}
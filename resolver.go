@@ -6,10 +6,14 @@ package clortho
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"sync"
-	"sync/atomic"
+	"time"
 
 	"github.com/jtacoma/uritemplates"
+	"github.com/xmidt-org/chronon"
 	"go.uber.org/multierr"
 )
 
@@ -24,11 +28,56 @@ var (
 
 	// ErrKeyNotFound indicates that a key could not be resolved, e.g. a key ID did not exist.
 	ErrKeyNotFound = errors.New("No such key exists")
+
+	// ErrNoKeyRing indicates that a Resolver was configured to refresh on a miss
+	// without a KeyRing to check after the refresh.
+	ErrNoKeyRing = errors.New("A KeyRing is required to refresh keys on a miss")
+
+	// ErrResolveRateLimited indicates that a key was not fetched because the Resolver's
+	// concurrency cap or rate limit was reached.  See WithResolveConcurrency and WithResolveRateLimit.
+	ErrResolveRateLimited = errors.New("Key resolution is rate limited")
 )
 
+// ResolveTimeoutError indicates that fetching a key took longer than a Resolver's
+// configured timeout.  See WithResolveTimeout.
+type ResolveTimeoutError struct {
+	// KeyID is the key ID being resolved.
+	KeyID string
+
+	// URI is the expanded URI used to fetch the key.
+	URI string
+
+	// Timeout is the Resolver's configured timeout.
+	Timeout time.Duration
+
+	// Err is the underlying error returned by the Fetcher.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (rte *ResolveTimeoutError) Error() string {
+	return fmt.Sprintf("Resolving key [%s] from %s timed out after %s: %s", rte.KeyID, rte.URI, rte.Timeout, rte.Err)
+}
+
+// Unwrap returns the underlying error, which allows errors.Is to match context.DeadlineExceeded.
+func (rte *ResolveTimeoutError) Unwrap() error {
+	return rte.Err
+}
+
+// ResolveFailure describes an unsuccessful attempt to fetch a key from one URI template.
+type ResolveFailure struct {
+	// URI is the expanded URI that was tried.
+	URI string
+
+	// Err is the error from that attempt.
+	Err error
+}
+
 // ResolveEvent holds information about a key ID that has been resolved.
 type ResolveEvent struct {
-	// URI is the actual, expanded URI used to obtain the key material.
+	// URI is the actual, expanded URI used to obtain the key material.  When fallback
+	// templates are configured, this is the URI that served the key or, if every attempt
+	// failed, the last URI tried.
 	URI string
 
 	// KeyID is the key ID that was resolved.
@@ -40,6 +89,48 @@ type ResolveEvent struct {
 	// Err holds any error that occurred while trying to fetch key material.
 	// If this field is set, Key will be nil.
 	Err error
+
+	// CachedMiss indicates that no fetch was attempted because this key ID was recently
+	// found not to exist.  Err holds the error from the original attempt, and URI holds
+	// the location used for that attempt.  See WithNegativeCache.
+	CachedMiss bool
+
+	// Cached indicates that no fetch was attempted because the key was already in the
+	// Resolver's KeyRing.  Only Resolver.ResolveAll produces such events.  Listeners that
+	// measure fetches, such as those in clorthometrics and clorthozap, ignore them.
+	Cached bool
+
+	// Failures holds the attempts that failed before the one described by URI and Err, in
+	// the order they were tried.  This field is only set when fallback templates were used.
+	// See WithFallbackTemplate.
+	Failures []ResolveFailure
+
+	// TTL is the time-to-live of the content that served the key, as reported by the Fetcher,
+	// e.g. from HTTP caching headers.  A Resolver's KeyRing holds the key on behalf of the
+	// resolve for this long.  This field is unset if there was no TTL.  See KeyRing.AddFromWithTTL.
+	TTL time.Duration
+
+	// Revalidate indicates that the content that served the key must not be reused without
+	// fetching it again, e.g. because of a no-store or no-cache directive.  Such a key is
+	// returned to its callers but is not added to a Resolver's KeyRing.  See ContentMeta.Revalidate.
+	Revalidate bool
+
+	// Refreshed indicates that the key was found by refreshing the sources of a Refresher
+	// rather than by fetching a URI template.  In that case, URI is the refresh source that
+	// served the key.  See WithRefreshOnMiss.
+	Refreshed bool
+}
+
+// ResolveResult is the outcome of resolving a single key ID with Resolver.ResolveAll.
+type ResolveResult struct {
+	// KeyID is the key ID that was resolved.
+	KeyID string
+
+	// Key is the resolved key.  This field is nil if Err is set.
+	Key Key
+
+	// Err is the error that occurred while resolving this key ID, if any.
+	Err error
 }
 
 // ResolveListener is a sink for ResolveEvents.
@@ -63,8 +154,29 @@ func NewExpander(rawTemplate string) (Expander, error) {
 // Resolver allows synchronous resolution of keys.
 type Resolver interface {
 	// Resolve attempts to locate a key with a given keyID (kid).
+	//
+	// The keyID is first checked by any configured KeyIDValidators.  An invalid keyID results
+	// in an *InvalidKeyIDError without any other processing, including events.
+	//
+	// Concurrent calls for the same keyID share a single fetch.  The context bounds how long
+	// each caller waits:  if it is canceled or its deadline passes first, Resolve returns the
+	// context's error.  One caller giving up does not affect the others, but once every caller
+	// waiting on a fetch has given up, that fetch is canceled and a later call starts a new one.
+	// The fetch itself is also bounded by the Resolver's timeout, if one is configured.
 	Resolve(ctx context.Context, keyID string) (Key, error)
 
+	// ResolveAll resolves several key IDs concurrently, as with Resolve, using a bounded
+	// number of workers.  Duplicate key IDs are resolved only once, and results are returned
+	// in the order each key ID first appears.  Unlike Resolve, each distinct, valid key ID produces
+	// exactly one ResolveEvent, and a key ID already in the KeyRing produces an event with Cached set.
+	// A key ID that fails validation produces no event, and its *InvalidKeyIDError is reported only
+	// in its ResolveResult.
+	//
+	// The returned error aggregates the errors for each key ID, and is nil only if every key
+	// ID was resolved.  Once the context is canceled or its deadline passes, no further fetches
+	// are started, and each remaining key ID's result holds the context's error.  See WithBatchWorkers.
+	ResolveAll(ctx context.Context, keyIDs ...string) ([]ResolveResult, error)
+
 	// AddListener attaches a sink for ResolveEvents.  Only events that
 	// occur after this method call will be dispatched to the given listener.
 	AddListener(ResolveListener) CancelListenerFunc
@@ -73,13 +185,17 @@ type Resolver interface {
 // NewResolver constructs a Resolver from a set of options.  By default, a Resolver
 // uses the DefaultLoader() and DefaultParser().
 //
-// If no URI template is supplied, this function returns ErrNoTemplate.
+// If neither a URI template nor a Refresher is supplied, this function returns ErrNoTemplate.
+// If a Refresher is supplied via WithRefreshOnMiss without a KeyRing, this function returns
+// ErrNoKeyRing.
 func NewResolver(options ...ResolverOption) (Resolver, error) {
 	var (
 		err error
 
 		r = &resolver{
-			pending: pendingResolverRequests{},
+			pending:      pendingResolverRequests{},
+			clock:        chronon.SystemClock(),
+			batchWorkers: DefaultResolveBatchWorkers,
 		}
 	)
 
@@ -91,12 +207,19 @@ func NewResolver(options ...ResolverOption) (Resolver, error) {
 		r.fetcher, _ = NewFetcher()
 	}
 
-	if r.keyIDExpander == nil {
-		r = nil
+	if r.keyIDExpander == nil && r.refresher == nil {
 		err = multierr.Append(err, ErrNoTemplate)
 	}
 
-	return r, err
+	if r.refresher != nil && r.keyRing == nil {
+		err = multierr.Append(err, ErrNoKeyRing)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 // pendingResolverRequest represents a resolve operation that is inflight.  Concurrent
@@ -105,14 +228,23 @@ func NewResolver(options ...ResolverOption) (Resolver, error) {
 type pendingResolverRequest struct {
 	keyID string
 	done  chan struct{}
-	value atomic.Value
+
+	// waiters is the number of callers waiting on this request, and cancel
+	// cancels its fetch.  These fields are guarded by the resolveLock.
+	waiters int
+	cancel  context.CancelFunc
+
+	// key and err are the results of the fetch.  These fields must
+	// only be read after done is closed.
+	key Key
+	err error
 }
 
 // pendingResolverRequests holds the key requests that are in-flight.  Map keys
 // are key IDs.  This type is not itself safe for concurrent access.
 type pendingResolverRequests map[string]*pendingResolverRequest
 
-// requestFor returns a pending request for a keyID.
+// requestFor returns a pending request for a keyID, adding the caller as a waiter.
 //
 // If wait is true, this is an existing request that is already in-flight within
 // another goroutine.  In this case, the caller should wait on the request's done channel.
@@ -130,14 +262,17 @@ func (prr pendingResolverRequests) requestFor(keyID string) (r *pendingResolverR
 		prr[keyID] = r
 	}
 
+	r.waiters++
 	return
 }
 
-// cleanup removes the pending request.  This method needs to be guarded
-// by an enclosing lock.
+// cleanup removes the pending request, so that subsequent calls will start a new
+// request.  A newer request for the same key ID is left alone.  This method needs
+// to be guarded by an enclosing lock.
 func (prr pendingResolverRequests) cleanup(request *pendingResolverRequest) {
-	delete(prr, request.keyID)
-	close(request.done)
+	if prr[request.keyID] == request {
+		delete(prr, request.keyID)
+	}
 }
 
 // resolver is the internal Resolver implementation.
@@ -148,8 +283,31 @@ type resolver struct {
 	resolveLock sync.Mutex
 	pending     pendingResolverRequests
 	keyRing     KeyRing
+	timeout     time.Duration
+
+	keyIDValidators []KeyIDValidator
+	batchWorkers    int
+
+	// the optional limits on outbound fetches
+	slots        chan struct{}
+	bucket       *tokenBucket
+	maxQueueWait time.Duration
+
+	// negativeCache is the optional record of key IDs that could not be found
+	negativeCache *negativeCache
+	clock         chronon.Clock
 
 	keyIDExpander Expander
+	fallbacks     []fallbackExpander
+
+	// refresher is the optional Refresher whose sources are refreshed on a miss
+	refresher Refresher
+}
+
+// fallbackExpander is a URI template tried when the primary template fails.
+type fallbackExpander struct {
+	expander Expander
+	timeout  time.Duration
 }
 
 func (r *resolver) dispatch(event ResolveEvent) {
@@ -158,6 +316,20 @@ func (r *resolver) dispatch(event ResolveEvent) {
 	})
 }
 
+// validateKeyID applies each configured validator to a key ID.
+func (r *resolver) validateKeyID(keyID string) error {
+	for _, v := range r.keyIDValidators {
+		if err := v(keyID); err != nil {
+			return &InvalidKeyIDError{
+				KeyID: keyID,
+				Err:   err,
+			}
+		}
+	}
+
+	return nil
+}
+
 func (r *resolver) checkKeyRing(keyID string) (k Key, ok bool) {
 	if r.keyRing != nil {
 		k, ok = r.keyRing.Get(keyID)
@@ -166,30 +338,164 @@ func (r *resolver) checkKeyRing(keyID string) (k Key, ok bool) {
 	return
 }
 
+// checkNegativeCache returns the event for a recent miss of the given key ID, if any.
+func (r *resolver) checkNegativeCache(keyID string) (event ResolveEvent, ok bool) {
+	if r.negativeCache != nil {
+		var entry negativeCacheEntry
+		if entry, ok = r.negativeCache.get(keyID, r.clock.Now()); ok {
+			event = ResolveEvent{
+				URI:        entry.location,
+				KeyID:      keyID,
+				Err:        entry.err,
+				CachedMiss: true,
+			}
+		}
+	}
+
+	return
+}
+
 func (r *resolver) waitForKey(ctx context.Context, request *pendingResolverRequest) (k Key, err error) {
+	var gaveUp bool
 	select {
 	case <-ctx.Done():
 		err = ctx.Err()
+		gaveUp = true
 
 	case <-request.done:
-		var ok bool
-		k, ok = request.value.Load().(Key)
-		if !ok {
-			err = ErrKeyNotFound
+		k, err = request.key, request.err
+	}
+
+	r.resolveLock.Lock()
+	request.waiters--
+	if gaveUp && request.waiters == 0 {
+		// nobody is waiting on this fetch anymore, so abandon it
+		r.pending.cleanup(request)
+		request.cancel()
+	}
+
+	r.resolveLock.Unlock()
+	return
+}
+
+// newFetchContext applies the given timeout, if positive, to a fetch.
+func newFetchContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, nopCancel
+}
+
+// shouldFallback tests if a failed fetch should be retried with the next template.
+// Missing keys, 404 responses, timeouts, and network errors are retried.
+func shouldFallback(err error) bool {
+	var (
+		hle *HTTPLoaderError
+		rte *ResolveTimeoutError
+		ne  net.Error
+	)
+
+	switch {
+	case errors.As(err, &hle):
+		return hle.StatusCode == http.StatusNotFound
+
+	case errors.Is(err, ErrKeyNotFound):
+		return true
+
+	case errors.As(err, &rte):
+		return true
+
+	case errors.As(err, &ne):
+		return true
+
+	default:
+		return false
+	}
+}
+
+// refreshKey refreshes the sources of this resolver's Refresher and checks the KeyRing again.
+// The location of a found key is the URI of the refresh source that served it.
+func (r *resolver) refreshKey(ctx context.Context, keyID string) (location string, k Key, err error) {
+	ctx, cancel := newFetchContext(ctx, r.timeout)
+	defer cancel()
+
+	_, err = r.refresher.RefreshAll(ctx, false)
+
+	var ok bool
+	if k, ok = r.checkKeyRing(keyID); ok {
+		err = nil
+		for _, origin := range r.keyRing.Origins(keyID) {
+			if origin.Kind == OriginRefresh {
+				location = origin.URI
+				break
+			}
 		}
+	} else if err == nil {
+		err = ErrKeyNotFound
 	}
 
 	return
 }
 
-func (r *resolver) fetchKey(ctx context.Context, keyID string, request *pendingResolverRequest) (location string, k Key, err error) {
-	location, err = r.keyIDExpander.Expand(map[string]interface{}{
+// fetchKey attempts to obtain a key, first by refreshing this resolver's Refresher, if
+// configured, and then by using the primary template followed by any fallbacks, in order.
+func (r *resolver) fetchKey(ctx context.Context, keyID string) (event ResolveEvent) {
+	event.KeyID = keyID
+	if r.refresher != nil {
+		event.URI, event.Key, event.Err = r.refreshKey(ctx, keyID)
+		if event.Err == nil || r.keyIDExpander == nil {
+			event.Refreshed = event.Err == nil
+			return
+		}
+
+		event.URI = ""
+	}
+
+	var meta ContentMeta
+	event.URI, event.Key, meta, event.Err = r.fetchFrom(ctx, keyID, r.keyIDExpander, r.timeout)
+	for i := 0; event.Err != nil && i < len(r.fallbacks) && shouldFallback(event.Err); i++ {
+		event.Failures = append(event.Failures, ResolveFailure{
+			URI: event.URI,
+			Err: event.Err,
+		})
+
+		timeout := r.fallbacks[i].timeout
+		if timeout <= 0 {
+			timeout = r.timeout
+		}
+
+		event.URI, event.Key, meta, event.Err = r.fetchFrom(ctx, keyID, r.fallbacks[i].expander, timeout)
+	}
+
+	if event.Err == nil {
+		event.TTL, event.Revalidate = meta.TTL, meta.Revalidate
+	}
+
+	return
+}
+
+// fetchFrom fetches a key using a single URI template.
+func (r *resolver) fetchFrom(ctx context.Context, keyID string, e Expander, timeout time.Duration) (location string, k Key, meta ContentMeta, err error) {
+	location, err = e.Expand(map[string]interface{}{
 		KeyIDParameterName: keyID,
 	})
 
+	ctx, cancel := newFetchContext(ctx, timeout)
+	defer cancel()
+
 	var keys []Key
 	if err == nil {
-		keys, _, err = r.fetcher.Fetch(ctx, location, ContentMeta{})
+		keys, meta, err = r.fetcher.Fetch(ctx, location, ContentMeta{})
+	}
+
+	if err != nil && timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = &ResolveTimeoutError{
+			KeyID:   keyID,
+			URI:     location,
+			Timeout: timeout,
+			Err:     err,
+		}
 	}
 
 	if err == nil {
@@ -219,49 +525,152 @@ func (r *resolver) fetchKey(ctx context.Context, keyID string, request *pendingR
 }
 
 func (r *resolver) Resolve(ctx context.Context, keyID string) (k Key, err error) {
-	var ok bool
-	if k, ok = r.checkKeyRing(keyID); ok {
+	if err = r.validateKeyID(keyID); err != nil {
+		return
+	}
+
+	k, _, err = r.resolveKey(ctx, keyID)
+	return
+}
+
+// resolveKey resolves a valid key ID.  If the key was already in the KeyRing,
+// cached is true and no ResolveEvent was dispatched.
+func (r *resolver) resolveKey(ctx context.Context, keyID string) (k Key, cached bool, err error) {
+	if k, cached = r.checkKeyRing(keyID); cached {
 		return
 	}
 
+	if event, miss := r.checkNegativeCache(keyID); miss {
+		r.dispatch(event)
+		return nil, false, event.Err
+	}
+
 	r.resolveLock.Lock()
-	if k, ok = r.checkKeyRing(keyID); ok {
+	if k, cached = r.checkKeyRing(keyID); cached {
 		r.resolveLock.Unlock()
 		return
 	}
 
 	request, wait := r.pending.requestFor(keyID)
-	r.resolveLock.Unlock()
+	if !wait {
+		// this goroutine is responsible for starting the fetch, which carries the values
+		// from ctx but runs until every waiter has given up
+		var fetchCtx context.Context
+		fetchCtx, request.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		go r.resolve(fetchCtx, request)
+	}
 
-	if wait {
-		// another goroutine is currently fetching the key, so wait for it to be done
-		k, err = r.waitForKey(ctx, request)
-	} else {
-		// this is the goroutine that is now responsible for fetching the key
-		var location string
-		location, k, err = r.fetchKey(ctx, keyID, request)
-
-		if err == nil {
-			if r.keyRing != nil {
-				r.keyRing.Add(k)
-			}
+	r.resolveLock.Unlock()
 
-			request.value.Store(k)
-		}
+	k, err = r.waitForKey(ctx, request)
+	return
+}
 
-		r.resolveLock.Lock()
-		r.pending.cleanup(request)
-		r.resolveLock.Unlock()
+// resolveOne resolves a key ID for ResolveAll, dispatching an event even when Resolve would not.
+func (r *resolver) resolveOne(ctx context.Context, keyID string) (Key, error) {
+	if err := r.validateKeyID(keyID); err != nil {
+		// key IDs are untrusted input, so an invalid one is reported only in its result
+		return nil, err
+	}
 
+	k, cached, err := r.resolveKey(ctx, keyID)
+	if cached {
 		r.dispatch(ResolveEvent{
-			URI:   location,
-			Key:   k,
+			KeyID:  keyID,
+			Key:    k,
+			Cached: true,
+		})
+	}
+
+	return k, err
+}
+
+// limitedFetch waits for this resolver's limits, if any, then fetches the key.
+func (r *resolver) limitedFetch(ctx context.Context, keyID string) ResolveEvent {
+	release, err := r.acquire()
+	if err != nil {
+		return ResolveEvent{
 			KeyID: keyID,
 			Err:   err,
-		})
+		}
 	}
 
-	return
+	defer release()
+	return r.fetchKey(ctx, keyID)
+}
+
+// resolve performs the fetch for a pending request and completes it.
+func (r *resolver) resolve(ctx context.Context, request *pendingResolverRequest) {
+	event := r.limitedFetch(ctx, request.keyID)
+	switch {
+	case event.Err == nil && !event.Refreshed && !event.Revalidate && r.keyRing != nil:
+		// keys found by refreshing are already in the ring under their refresh origins
+		r.keyRing.AddFromWithTTL(KeyOrigin{Kind: OriginResolve, URI: event.URI}, event.TTL, event.Key)
+
+	case event.Err != nil && r.negativeCache != nil && ctx.Err() == nil:
+		// an abandoned fetch says nothing about whether the key exists
+		r.negativeCache.put(request.keyID, event.URI, event.Err, r.clock.Now())
+	}
+
+	request.key, request.err = event.Key, event.Err
+
+	r.resolveLock.Lock()
+	r.pending.cleanup(request)
+	request.cancel()
+	r.resolveLock.Unlock()
+
+	r.dispatch(event)
+	close(request.done)
+}
+
+func (r *resolver) ResolveAll(ctx context.Context, keyIDs ...string) ([]ResolveResult, error) {
+	var (
+		results = make([]ResolveResult, 0, len(keyIDs))
+		seen    = make(map[string]bool, len(keyIDs))
+	)
+
+	for _, keyID := range keyIDs {
+		if !seen[keyID] {
+			seen[keyID] = true
+			results = append(results, ResolveResult{KeyID: keyID})
+		}
+	}
+
+	workers := min(r.batchWorkers, len(results))
+	var (
+		wg   sync.WaitGroup
+		next = make(chan *ResolveResult, len(results))
+	)
+
+	for i := range results {
+		next <- &results[i]
+	}
+
+	close(next)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for result := range next {
+				if err := ctx.Err(); err != nil {
+					// don't start any more fetches once the caller has given up
+					result.Err = err
+					continue
+				}
+
+				result.Key, result.Err = r.resolveOne(ctx, result.KeyID)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var err error
+	for _, result := range results {
+		err = multierr.Append(err, result.Err)
+	}
+
+	return results, err
 }
 
 func (r *resolver) AddListener(l ResolveListener) CancelListenerFunc {
@@ -6,11 +6,17 @@ package clortho
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/chronon"
 )
 
 const (
@@ -106,6 +112,50 @@ func (suite *ResolverSuite) TestDefault() {
 	suite.NotNil(r.(*resolver).fetcher)
 }
 
+func (suite *ResolverSuite) TestConfigAfterOptions() {
+	r := suite.newResolver(
+		WithResolveTimeout(time.Second),
+		WithResolveConcurrency(2),
+		WithResolveRateLimit(10.0, 5),
+		WithResolveQueueWait(time.Minute),
+		WithBatchWorkers(3),
+		WithNegativeCache(time.Minute, 0),
+
+		// none of these fields are set, so none of the options above are undone
+		WithConfig(Config{
+			Resolve: ResolveConfig{
+				Template: "http://getkeys.com/{keyID}",
+			},
+		}),
+	)
+
+	suite.Require().IsType((*resolver)(nil), r)
+	impl := r.(*resolver)
+	suite.Equal(time.Second, impl.timeout)
+	suite.Equal(2, cap(impl.slots))
+	suite.NotNil(impl.bucket)
+	suite.Equal(time.Minute, impl.maxQueueWait)
+	suite.Equal(3, impl.batchWorkers)
+	suite.NotNil(impl.negativeCache)
+
+	// set fields still override earlier options
+	r = suite.newResolver(
+		WithResolveTimeout(time.Second),
+		WithNegativeCache(time.Minute, 0),
+		WithConfig(Config{
+			Resolve: ResolveConfig{
+				Template:         "http://getkeys.com/{keyID}",
+				Timeout:          time.Hour,
+				NegativeCacheTTL: -1,
+			},
+		}),
+	)
+
+	impl = r.(*resolver)
+	suite.Equal(time.Hour, impl.timeout)
+	suite.Nil(impl.negativeCache)
+}
+
 func (suite *ResolverSuite) TestSingleKey() {
 	var (
 		f = new(mockFetcher)
@@ -115,7 +165,7 @@ func (suite *ResolverSuite) TestSingleKey() {
 		)
 	)
 
-	f.ExpectFetch(context.Background(), "http://getkeys.com/testKey", ContentMeta{}).
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
 		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
 		Twice()
 
@@ -142,7 +192,7 @@ func (suite *ResolverSuite) TestMultipleKeys() {
 		)
 	)
 
-	f.ExpectFetch(context.Background(), "http://getkeys.com/testKey", ContentMeta{}).
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
 		Return(suite.testKeySet, ContentMeta{}, error(nil)).
 		Twice()
 
@@ -173,7 +223,7 @@ func (suite *ResolverSuite) TestWithKeyRing() {
 		)
 	)
 
-	f.ExpectFetch(context.Background(), "http://getkeys.com/testKey", ContentMeta{}).
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
 		Return(suite.testKeySet, ContentMeta{}, error(nil)).
 		Twice()
 
@@ -195,6 +245,10 @@ func (suite *ResolverSuite) TestWithKeyRing() {
 	key, ok := keyRing.Get("testKey")
 	suite.True(ok)
 	suite.Equal(suite.testKey, key)
+	suite.Equal(
+		[]KeyOrigin{{Kind: OriginResolve, URI: "http://getkeys.com/testKey"}},
+		keyRing.Origins("testKey"),
+	)
 
 	key, err = r.Resolve(context.Background(), "testKey")
 	suite.Require().NoError(err)
@@ -226,7 +280,7 @@ func (suite *ResolverSuite) TestNoKey() {
 		)
 	)
 
-	f.ExpectFetch(context.Background(), "http://getkeys.com/testKey", ContentMeta{}).
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
 		Return([]Key{}, ContentMeta{}, error(nil)).
 		Twice()
 
@@ -251,7 +305,7 @@ func (suite *ResolverSuite) TestMissingKey() {
 		)
 	)
 
-	f.ExpectFetch(context.Background(), "http://getkeys.com/nosuchKey", ContentMeta{}).
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/nosuchKey", ContentMeta{}).
 		Return(suite.testKeySet, ContentMeta{}, error(nil)).
 		Once()
 
@@ -273,7 +327,7 @@ func (suite *ResolverSuite) TestFetcherError() {
 		)
 	)
 
-	f.ExpectFetch(context.Background(), "http://getkeys.com/testKey", ContentMeta{}).
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
 		Return([]Key{}, ContentMeta{}, expectedError).
 		Once()
 
@@ -305,7 +359,7 @@ func (suite *ResolverSuite) TestConcurrentFetch() {
 		results    = make(chan result, 3)
 	)
 
-	f.ExpectFetch(context.Background(), "http://getkeys.com/testKey", ContentMeta{}).
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
 		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
 		Once()
 
@@ -348,6 +402,840 @@ func (suite *ResolverSuite) TestConcurrentFetch() {
 	f.AssertExpectations(suite.T())
 }
 
+func (suite *ResolverSuite) TestNegativeCache() {
+	var (
+		listener = new(mockResolveListener)
+
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithNegativeCache(time.Minute, 0),
+		)
+
+		fc = chronon.NewFakeClock(time.Now())
+
+		noStore    = &HTTPLoaderError{StatusCode: http.StatusNotFound, HasTTL: true}
+		cacheFor   = &HTTPLoaderError{StatusCode: http.StatusNotFound, HasTTL: true, TTL: 10 * time.Minute}
+		otherError = &HTTPLoaderError{StatusCode: http.StatusInternalServerError}
+	)
+
+	suite.Require().IsType((*resolver)(nil), r)
+	r.(*resolver).clock = fc
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/unknown", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, error(nil)).
+		Twice()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/noStore", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, noStore).
+		Twice()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/cacheFor", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, cacheFor).
+		Once()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/other", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, otherError).
+		Twice()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:   "http://getkeys.com/unknown",
+		KeyID: "unknown",
+		Err:   ErrKeyNotFound,
+	}).Once()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:        "http://getkeys.com/unknown",
+		KeyID:      "unknown",
+		Err:        ErrKeyNotFound,
+		CachedMiss: true,
+	}).Once()
+
+	cancel := r.AddListener(listener)
+
+	_, err := r.Resolve(context.Background(), "unknown")
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	// the miss is remembered, so this should not fetch
+	_, err = r.Resolve(context.Background(), "unknown")
+	suite.ErrorIs(err, ErrKeyNotFound)
+	cancel()
+
+	// a 404 that cannot be cached is never remembered
+	for i := 0; i < 2; i++ {
+		_, err = r.Resolve(context.Background(), "noStore")
+		suite.ErrorIs(err, noStore)
+	}
+
+	// errors other than a missing key are never remembered
+	for i := 0; i < 2; i++ {
+		_, err = r.Resolve(context.Background(), "other")
+		suite.ErrorIs(err, otherError)
+	}
+
+	_, err = r.Resolve(context.Background(), "cacheFor")
+	suite.ErrorIs(err, cacheFor)
+
+	// the configured TTL has elapsed, but the 404 supplied a longer lifetime
+	fc.Set(fc.Now().Add(2 * time.Minute))
+	_, err = r.Resolve(context.Background(), "cacheFor")
+	suite.ErrorIs(err, cacheFor)
+
+	_, err = r.Resolve(context.Background(), "unknown")
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestNegativeCacheSize() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithNegativeCache(time.Minute, 1),
+		)
+	)
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/first", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, error(nil)).
+		Twice()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/second", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, error(nil)).
+		Once()
+
+	_, err := r.Resolve(context.Background(), "first")
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	// this evicts the first key ID
+	_, err = r.Resolve(context.Background(), "second")
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	_, err = r.Resolve(context.Background(), "second")
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	_, err = r.Resolve(context.Background(), "first")
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	f.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestNoStore() {
+	var (
+		requests atomic.Int32
+
+		server = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			response.Header().Set("Content-Type", MediaTypeJWK)
+			response.Header().Set("Cache-Control", "no-store")
+			response.Write([]byte(resolverTestKey))
+		}))
+
+		keyRing  = NewKeyRing()
+		listener = new(mockResolveListener)
+		r        = suite.newResolver(
+			WithKeyRing(keyRing),
+			WithKeyIDTemplate(server.URL+"/{keyID}"),
+		)
+	)
+
+	defer server.Close()
+	listener.On("OnResolveEvent", mock.MatchedBy(func(event ResolveEvent) bool {
+		return event.Err == nil && event.Revalidate
+	})).Twice()
+
+	r.AddListener(listener)
+
+	// the key is returned, but never cached, so each Resolve fetches it again
+	for i := 0; i < 2; i++ {
+		key, err := r.Resolve(context.Background(), "testKey")
+		suite.Require().NoError(err)
+		suite.Equal("testKey", key.KeyID())
+
+		_, ok := keyRing.Get("testKey")
+		suite.False(ok)
+	}
+
+	suite.Equal(int32(2), requests.Load())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestTimeout() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithResolveTimeout(10*time.Millisecond),
+		)
+
+		rte *ResolveTimeoutError
+	)
+
+	f.ExpectFetchCtx(
+		func(ctx context.Context) bool {
+			_, ok := ctx.Deadline()
+			return ok
+		},
+		"http://getkeys.com/testKey",
+		ContentMeta{},
+	).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return([]Key{}, ContentMeta{}, context.DeadlineExceeded).Once()
+
+	key, err := r.Resolve(context.Background(), "testKey")
+	suite.Nil(key)
+	suite.Require().ErrorAs(err, &rte)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+	suite.Equal("testKey", rte.KeyID)
+	suite.Equal("http://getkeys.com/testKey", rte.URI)
+	suite.Equal(10*time.Millisecond, rte.Timeout)
+
+	f.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) waitForWaiters(r Resolver, keyID string, expected int) {
+	impl := r.(*resolver)
+	suite.Eventually(
+		func() bool {
+			impl.resolveLock.Lock()
+			defer impl.resolveLock.Unlock()
+			request, ok := impl.pending[keyID]
+			return ok && request.waiters == expected
+		},
+		2*time.Second,
+		time.Millisecond,
+	)
+}
+
+func (suite *ResolverSuite) TestCallerCanceled() {
+	var (
+		keyRing = NewKeyRing()
+
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithKeyRing(keyRing),
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+		)
+
+		fetchStarted = make(chan struct{})
+		fetchRelease = make(chan struct{})
+		fetchErr     = make(chan error, 1)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		canceled    = make(chan error, 1)
+		results     = make(chan error, 1)
+	)
+
+	defer cancel()
+	f.ExpectFetchCtx(
+		func(context.Context) bool { return true },
+		"http://getkeys.com/testKey",
+		ContentMeta{},
+	).Run(func(args mock.Arguments) {
+		close(fetchStarted)
+		<-fetchRelease
+		fetchErr <- args.Get(0).(context.Context).Err()
+	}).Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).Once()
+
+	go func() {
+		_, err := r.Resolve(ctx, "testKey")
+		canceled <- err
+	}()
+
+	select {
+	case <-fetchStarted:
+	case <-time.After(2 * time.Second):
+		suite.FailNow("The fetch did not start")
+	}
+
+	go func() {
+		_, err := r.Resolve(context.Background(), "testKey")
+		results <- err
+	}()
+
+	suite.waitForWaiters(r, "testKey", 2)
+
+	// the first caller gives up, but the fetch continues for the other caller
+	cancel()
+	suite.ErrorIs(<-canceled, context.Canceled)
+
+	close(fetchRelease)
+	suite.NoError(<-fetchErr)
+	suite.NoError(<-results)
+
+	key, ok := keyRing.Get("testKey")
+	suite.True(ok)
+	suite.Equal(suite.testKey, key)
+
+	f.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestAllCallersCanceled() {
+	var (
+		requests = make(chan struct{}, 2)
+		canceled = make(chan struct{}, 2)
+
+		server = httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+			// this server never responds
+			requests <- struct{}{}
+			<-request.Context().Done()
+			canceled <- struct{}{}
+		}))
+
+		r = suite.newResolver(
+			WithKeyIDTemplate(server.URL+"/{keyID}"),
+			WithResolveConcurrency(1),
+		)
+	)
+
+	defer server.Close()
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		key, err := r.Resolve(ctx, "testKey")
+		cancel()
+		suite.Nil(key)
+		suite.ErrorIs(err, context.DeadlineExceeded)
+
+		// each Resolve reaches the server, rather than joining an abandoned fetch
+		// or being rate limited by the concurrency slot it holds
+		select {
+		case <-requests:
+		case <-time.After(2 * time.Second):
+			suite.FailNow("The fetch did not reach the server")
+		}
+
+		select {
+		case <-canceled:
+		case <-time.After(2 * time.Second):
+			suite.FailNow("The abandoned fetch was not canceled")
+		}
+	}
+}
+
+func (suite *ResolverSuite) TestRateLimit() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithResolveRateLimit(1.0, 1),
+		)
+
+		fc = chronon.NewFakeClock(time.Now())
+	)
+
+	suite.Require().IsType((*resolver)(nil), r)
+	r.(*resolver).clock = fc
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/first", ContentMeta{}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/second", ContentMeta{}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	_, err := r.Resolve(context.Background(), "first")
+	suite.NoError(err)
+
+	// the bucket is empty, and there is no queue wait
+	_, err = r.Resolve(context.Background(), "second")
+	suite.ErrorIs(err, ErrResolveRateLimited)
+
+	fc.Set(fc.Now().Add(time.Second))
+	_, err = r.Resolve(context.Background(), "second")
+	suite.NoError(err)
+
+	f.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestConcurrencyLimit() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithResolveConcurrency(1),
+		)
+
+		fetchStarted = make(chan struct{})
+		fetchRelease = make(chan struct{})
+		results      = make(chan error, 1)
+	)
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/first", ContentMeta{}).
+		Run(func(mock.Arguments) {
+			close(fetchStarted)
+			<-fetchRelease
+		}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/second", ContentMeta{}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	go func() {
+		_, err := r.Resolve(context.Background(), "first")
+		results <- err
+	}()
+
+	select {
+	case <-fetchStarted:
+	case <-time.After(2 * time.Second):
+		suite.FailNow("The fetch did not start")
+	}
+
+	// the only slot is taken, and there is no queue wait
+	_, err := r.Resolve(context.Background(), "second")
+	suite.ErrorIs(err, ErrResolveRateLimited)
+
+	close(fetchRelease)
+	suite.NoError(<-results)
+
+	_, err = r.Resolve(context.Background(), "second")
+	suite.NoError(err)
+
+	f.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestQueueWait() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithResolveConcurrency(1),
+			WithResolveQueueWait(time.Minute),
+		)
+
+		fetchStarted = make(chan struct{})
+		fetchRelease = make(chan struct{})
+		results      = make(chan error, 2)
+	)
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/first", ContentMeta{}).
+		Run(func(mock.Arguments) {
+			close(fetchStarted)
+			<-fetchRelease
+		}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/second", ContentMeta{}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	go func() {
+		_, err := r.Resolve(context.Background(), "first")
+		results <- err
+	}()
+
+	select {
+	case <-fetchStarted:
+	case <-time.After(2 * time.Second):
+		suite.FailNow("The fetch did not start")
+	}
+
+	// this fetch waits in the queue for the first one to finish
+	go func() {
+		_, err := r.Resolve(context.Background(), "second")
+		results <- err
+	}()
+
+	close(fetchRelease)
+	suite.NoError(<-results)
+	suite.NoError(<-results)
+
+	f.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestKeyIDValidation() {
+	var (
+		custom = errors.New("custom")
+
+		listener = new(mockResolveListener)
+		f        = new(mockFetcher)
+		r        = suite.newResolver(
+			WithFetcher(f),
+			WithConfig(Config{
+				Resolve: ResolveConfig{
+					Template:       "http://getkeys.com/{keyID}",
+					KeyIDMaxLength: 10,
+					KeyIDPattern:   "^[A-Za-z0-9]+$",
+				},
+			}),
+			WithKeyIDValidators(func(keyID string) error {
+				if keyID == "rejected" {
+					return custom
+				}
+
+				return nil
+			}),
+		)
+
+		ikie *InvalidKeyIDError
+	)
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:   "http://getkeys.com/testKey",
+		KeyID: "testKey",
+		Key:   suite.testKey,
+	}).Once()
+
+	r.AddListener(listener)
+
+	testCases := []struct {
+		keyID    string
+		expected error
+	}{
+		{keyID: "thisKeyIDIsTooLong", expected: ErrKeyIDTooLong},
+		{keyID: "../admin", expected: ErrKeyIDInvalidCharacters},
+		{keyID: "", expected: ErrKeyIDInvalidCharacters},
+		{keyID: "rejected", expected: custom},
+	}
+
+	for _, testCase := range testCases {
+		key, err := r.Resolve(context.Background(), testCase.keyID)
+		suite.Nil(key)
+		suite.Require().ErrorAs(err, &ikie)
+		suite.Equal(testCase.keyID, ikie.KeyID)
+		suite.ErrorIs(err, testCase.expected)
+	}
+
+	// untrusted key IDs are not echoed in error messages
+	_, err := r.Resolve(context.Background(), "thisKeyIDIsTooLong")
+	suite.NotContains(err.Error(), "thisKeyIDIsTooLong")
+
+	key, err := r.Resolve(context.Background(), "testKey")
+	suite.NoError(err)
+	suite.Equal(suite.testKey, key)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestInvalidKeyIDPattern() {
+	r, err := NewResolver(WithConfig(Config{
+		Resolve: ResolveConfig{
+			Template:     "http://getkeys.com/{keyID}",
+			KeyIDPattern: "[",
+		},
+	}))
+
+	// the template is valid, but the Resolver is still discarded
+	suite.Error(err)
+	suite.Nil(r)
+}
+
+func (suite *ResolverSuite) TestFallbackTemplates() {
+	var (
+		notFound     = &HTTPLoaderError{Location: "http://primary.com/testKey", StatusCode: http.StatusNotFound}
+		networkError = &url.Error{Op: "Get", URL: "http://regional.com/testKey", Err: errors.New("connection refused")}
+
+		listener = new(mockResolveListener)
+		f        = new(mockFetcher)
+		r        = suite.newResolver(
+			WithFetcher(f),
+			WithConfig(Config{
+				Resolve: ResolveConfig{
+					Template: "http://primary.com/{keyID}",
+					Fallbacks: []ResolveTemplate{
+						{Template: "http://regional.com/{keyID}"},
+						{Template: "http://legacy.com/{keyID}", Timeout: time.Minute},
+					},
+				},
+			}),
+		)
+	)
+
+	f.ExpectFetch(mock.Anything, "http://primary.com/testKey", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, notFound).
+		Once()
+
+	f.ExpectFetch(mock.Anything, "http://regional.com/testKey", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, networkError).
+		Once()
+
+	f.ExpectFetchCtx(
+		func(ctx context.Context) bool {
+			_, ok := ctx.Deadline()
+			return ok
+		},
+		"http://legacy.com/testKey",
+		ContentMeta{},
+	).Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).Once()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:   "http://legacy.com/testKey",
+		KeyID: "testKey",
+		Key:   suite.testKey,
+		Failures: []ResolveFailure{
+			{URI: "http://primary.com/testKey", Err: notFound},
+			{URI: "http://regional.com/testKey", Err: networkError},
+		},
+	}).Once()
+
+	r.AddListener(listener)
+
+	key, err := r.Resolve(context.Background(), "testKey")
+	suite.NoError(err)
+	suite.Equal(suite.testKey, key)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestNoFallback() {
+	var (
+		serverError = &HTTPLoaderError{Location: "http://primary.com/testKey", StatusCode: http.StatusInternalServerError}
+
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://primary.com/{keyID}"),
+			WithFallbackTemplate("http://legacy.com/{keyID}", 0),
+		)
+	)
+
+	// only missing keys, 404s, timeouts, and network errors cause a fallback
+	f.ExpectFetch(mock.Anything, "http://primary.com/testKey", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, serverError).
+		Once()
+
+	key, err := r.Resolve(context.Background(), "testKey")
+	suite.Nil(key)
+	suite.ErrorIs(err, serverError)
+
+	f.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestRefreshOnMiss() {
+	var (
+		keyRing  = NewKeyRing()
+		listener = new(mockResolveListener)
+
+		f      = new(mockFetcher)
+		rf, rr = NewRefresher(
+			WithFetcher(f),
+			WithSources(RefreshSource{
+				URI:         "http://getkeys.com/keys",
+				MinInterval: time.Nanosecond,
+				Required:    true,
+			}),
+		)
+	)
+
+	suite.Require().NoError(rr)
+	rf.AddListener(keyRing)
+
+	// the initial fetch doesn't have the key, but subsequent ones do
+	f.On("Fetch", mock.Anything, "http://getkeys.com/keys", mock.Anything).
+		Return([]Key{}, ContentMeta{}, error(nil)).
+		Once()
+
+	f.On("Fetch", mock.Anything, "http://getkeys.com/keys", mock.Anything).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Twice()
+
+	suite.Require().NoError(rf.Start(context.Background()))
+	defer rf.Stop(context.Background())
+
+	r := suite.newResolver(
+		WithKeyRing(keyRing),
+		WithRefreshOnMiss(rf),
+	)
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:       "http://getkeys.com/keys",
+		KeyID:     "testKey",
+		Key:       suite.testKey,
+		Refreshed: true,
+	}).Once()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		KeyID: "nosuch",
+		Err:   ErrKeyNotFound,
+	}).Once()
+
+	r.AddListener(listener)
+
+	key, err := r.Resolve(context.Background(), "testKey")
+	suite.Require().NoError(err)
+	suite.Equal(suite.testKey, key)
+
+	// the key is held only by its refresh source
+	suite.Equal(
+		[]KeyOrigin{{Kind: OriginRefresh, URI: "http://getkeys.com/keys"}},
+		keyRing.Origins("testKey"),
+	)
+
+	key, err = r.Resolve(context.Background(), "nosuch")
+	suite.Nil(key)
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestRefreshOnMissNoKeyRing() {
+	rf, err := NewRefresher()
+	suite.Require().NoError(err)
+
+	r, err := NewResolver(WithRefreshOnMiss(rf))
+	suite.ErrorIs(err, ErrNoKeyRing)
+	suite.Nil(r)
+}
+
+func (suite *ResolverSuite) TestResolveAll() {
+	var (
+		listener = new(mockResolveListener)
+
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithBatchWorkers(2),
+		)
+
+		anotherKey = suite.testKeySet[2]
+	)
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/testKey", ContentMeta{}).
+		Return([]Key{suite.testKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/anotherKey", ContentMeta{}).
+		Return([]Key{anotherKey}, ContentMeta{}, error(nil)).
+		Once()
+
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/nosuch", ContentMeta{}).
+		Return([]Key{}, ContentMeta{}, error(nil)).
+		Once()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:   "http://getkeys.com/testKey",
+		KeyID: "testKey",
+		Key:   suite.testKey,
+	}).Once()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:   "http://getkeys.com/anotherKey",
+		KeyID: "anotherKey",
+		Key:   anotherKey,
+	}).Once()
+
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		URI:   "http://getkeys.com/nosuch",
+		KeyID: "nosuch",
+		Err:   ErrKeyNotFound,
+	}).Once()
+
+	r.AddListener(listener)
+
+	results, err := r.ResolveAll(context.Background(), "testKey", "nosuch", "testKey", "anotherKey")
+	suite.ErrorIs(err, ErrKeyNotFound)
+	suite.Equal(
+		[]ResolveResult{
+			{KeyID: "testKey", Key: suite.testKey},
+			{KeyID: "nosuch", Err: ErrKeyNotFound},
+			{KeyID: "anotherKey", Key: anotherKey},
+		},
+		results,
+	)
+
+	results, err = r.ResolveAll(context.Background())
+	suite.NoError(err)
+	suite.Empty(results)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestResolveAllEvents() {
+	var (
+		listener = new(mockResolveListener)
+
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithKeyIDValidators(KeyIDMaxLength(10)),
+			WithKeyRing(NewKeyRing(suite.testKey)),
+		)
+	)
+
+	// neither key ID is fetched, and only the valid one produces an event
+	listener.ExpectOnResolveEvent(ResolveEvent{
+		KeyID:  "testKey",
+		Key:    suite.testKey,
+		Cached: true,
+	}).Once()
+
+	r.AddListener(listener)
+
+	results, err := r.ResolveAll(context.Background(), "testKey", "thisKeyIDIsTooLong", "testKey")
+	suite.ErrorIs(err, ErrKeyIDTooLong)
+	suite.Require().Len(results, 2)
+	suite.Equal(suite.testKey, results[0].Key)
+	suite.NoError(results[0].Err)
+	suite.ErrorIs(results[1].Err, ErrKeyIDTooLong)
+
+	// Resolve does not dispatch events for either case
+	k, err := r.Resolve(context.Background(), "testKey")
+	suite.NoError(err)
+	suite.Equal(suite.testKey, k)
+
+	_, err = r.Resolve(context.Background(), "thisKeyIDIsTooLong")
+	suite.ErrorIs(err, ErrKeyIDTooLong)
+
+	f.AssertExpectations(suite.T())
+	listener.AssertExpectations(suite.T())
+}
+
+func (suite *ResolverSuite) TestResolveAllCanceled() {
+	var (
+		f = new(mockFetcher)
+		r = suite.newResolver(
+			WithFetcher(f),
+			WithKeyIDTemplate("http://getkeys.com/{keyID}"),
+			WithBatchWorkers(1),
+		)
+
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	defer cancel()
+
+	// the caller gives up during the first fetch, so the others are never started
+	f.ExpectFetch(mock.Anything, "http://getkeys.com/first", ContentMeta{}).
+		Run(func(args mock.Arguments) {
+			cancel()
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return([]Key{}, ContentMeta{}, context.Canceled).
+		Once()
+
+	results, err := r.ResolveAll(ctx, "first", "second", "third")
+	suite.ErrorIs(err, context.Canceled)
+	suite.Require().Len(results, 3)
+	for _, result := range results {
+		suite.Nil(result.Key)
+		suite.ErrorIs(result.Err, context.Canceled)
+	}
+
+	f.AssertExpectations(suite.T())
+}
+
 func TestResolver(t *testing.T) {
 	suite.Run(t, new(ResolverSuite))
 }
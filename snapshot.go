@@ -0,0 +1,393 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/cert"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/xmidt-org/chronon"
+	"go.uber.org/multierr"
+)
+
+var (
+	// ErrNoSnapshotPath indicates that a Snapshotter was created without a file path.
+	ErrNoSnapshotPath = errors.New("A snapshot path is required")
+
+	// ErrNoSnapshotKeyRing indicates that a Snapshotter was created without a KeyRing.
+	ErrNoSnapshotKeyRing = errors.New("A KeyRing is required to seed keys from a snapshot")
+
+	// ErrSnapshotTooOld indicates that a snapshot was older than the maximum age
+	// allowed by WithMaxSnapshotAge.
+	ErrSnapshotTooOld = errors.New("The snapshot is older than the maximum snapshot age")
+)
+
+// Snapshotter persists the public keys and ContentMeta of each refresh source to a local
+// file, so that a KeyRing can be seeded with them when an application restarts, e.g. during
+// an outage of the key servers.
+//
+// A Snapshotter is a RefreshListener.  It should be added to the same Refresher as the
+// KeyRing it seeds.  Each successful RefreshEvent that changes a source's keys or ContentMeta
+// rewrites the snapshot file atomically.  Keys that were resolved or added ad hoc are not
+// persisted, and neither are symmetric keys.
+type Snapshotter interface {
+	RefreshListener
+
+	// Seed reads the snapshot file and adds each source's keys to the KeyRing with an
+	// OriginSnapshot origin.  Seeded keys remain in the KeyRing until their source next
+	// refreshes successfully, at which point they are replaced by the refreshed keys.
+	// Seed should be called before the Refresher is started.  Sources that have already
+	// refreshed are not seeded.
+	//
+	// This method returns the count of keys added to the KeyRing.  If there is no snapshot
+	// file, this method does nothing and returns a nil error.  If the snapshot is older than
+	// the maximum age, no keys are added and ErrSnapshotTooOld is returned.
+	Seed() (int, error)
+
+	// Source returns the keys and ContentMeta most recently seeded or persisted for the
+	// refresh source with the given URI.  If there are none, the last return is false.
+	Source(uri string) (Keys, ContentMeta, bool)
+}
+
+// SnapshotterOption is a configurable option passed to NewSnapshotter.
+type SnapshotterOption interface {
+	applyToSnapshotter(*snapshotter) error
+}
+
+type snapshotterOptionFunc func(*snapshotter) error
+
+func (sof snapshotterOptionFunc) applyToSnapshotter(s *snapshotter) error { return sof(s) }
+
+// WithMaxSnapshotAge sets the maximum age of a snapshot that Snapshotter.Seed will use.
+// The age is measured from when the snapshot file was last written.
+//
+// By default, there is no maximum age.  A non-positive value also disables it.
+func WithMaxSnapshotAge(d time.Duration) SnapshotterOption {
+	return snapshotterOptionFunc(func(s *snapshotter) error {
+		s.maxAge = d
+		return nil
+	})
+}
+
+// WithSnapshotErrorHandler sets the closure that receives any error that occurs while
+// writing the snapshot file in response to a RefreshEvent.  By default, such errors are ignored.
+func WithSnapshotErrorHandler(f func(error)) SnapshotterOption {
+	return snapshotterOptionFunc(func(s *snapshotter) error {
+		s.onError = f
+		return nil
+	})
+}
+
+// NewSnapshotter creates a Snapshotter that persists to the file at the given path and
+// seeds the given KeyRing.  The directory containing the file must already exist.
+func NewSnapshotter(kr KeyRing, path string, options ...SnapshotterOption) (Snapshotter, error) {
+	var (
+		err error
+		s   = &snapshotter{
+			keyRing: kr,
+			path:    path,
+			clock:   chronon.SystemClock(),
+			sources: make(map[string]snapshotSource),
+		}
+	)
+
+	if kr == nil {
+		err = multierr.Append(err, ErrNoSnapshotKeyRing)
+	}
+
+	if len(path) == 0 {
+		err = multierr.Append(err, ErrNoSnapshotPath)
+	}
+
+	for _, o := range options {
+		err = multierr.Append(err, o.applyToSnapshotter(s))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// snapshotFile is the persisted form of a snapshot.
+type snapshotFile struct {
+	SavedAt time.Time            `json:"savedAt"`
+	Sources []snapshotFileSource `json:"sources"`
+}
+
+// snapshotFileSource is the persisted form of a single refresh source.  Keys
+// holds a JWK set.
+type snapshotFileSource struct {
+	URI  string          `json:"uri"`
+	Meta ContentMeta     `json:"meta"`
+	Keys json.RawMessage `json:"keys"`
+}
+
+// snapshotSource is the in-memory state of a single refresh source.
+type snapshotSource struct {
+	keys Keys
+	meta ContentMeta
+}
+
+// snapshotter is the internal Snapshotter implementation.
+type snapshotter struct {
+	keyRing KeyRing
+	path    string
+	maxAge  time.Duration
+	onError func(error)
+	clock   chronon.Clock
+
+	lock    sync.Mutex
+	sources map[string]snapshotSource
+}
+
+func (s *snapshotter) Seed() (n int, err error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	var sf snapshotFile
+	if err = json.Unmarshal(data, &sf); err != nil {
+		return 0, fmt.Errorf("Invalid snapshot %s: %w", s.path, err)
+	}
+
+	if s.maxAge > 0 && s.clock.Now().Sub(sf.SavedAt) > s.maxAge {
+		return 0, ErrSnapshotTooOld
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, source := range sf.Sources {
+		if _, ok := s.sources[source.URI]; ok {
+			// this source has already refreshed, or has already been seeded
+			continue
+		}
+
+		keys, parseErr := JWKSetParser{}.Parse(MediaTypeJWKSet, source.Keys)
+		if parseErr != nil {
+			err = multierr.Append(err, fmt.Errorf("Invalid snapshot keys for %s: %w", source.URI, parseErr))
+			continue
+		}
+
+		s.sources[source.URI] = snapshotSource{
+			keys: keys,
+			meta: source.Meta,
+		}
+
+		n += s.keyRing.AddFrom(
+			KeyOrigin{Kind: OriginSnapshot, URI: source.URI},
+			keys...,
+		)
+	}
+
+	return
+}
+
+func (s *snapshotter) Source(uri string) (Keys, ContentMeta, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ss, ok := s.sources[uri]
+	if !ok {
+		return nil, ContentMeta{}, false
+	}
+
+	keys := make(Keys, len(ss.keys))
+	copy(keys, ss.keys)
+	return keys, ss.meta, true
+}
+
+func (s *snapshotter) OnRefreshEvent(event RefreshEvent) {
+	if event.Err != nil {
+		// the keys in this event are the same ones that were last persisted
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	previous, ok := s.sources[event.URI]
+	switch {
+	case len(event.Keys) == 0 && !ok:
+		return
+
+	case len(event.Keys) == 0:
+		// the source is either empty or was removed from the Refresher
+		delete(s.sources, event.URI)
+
+	case ok && len(event.New) == 0 && len(event.Deleted) == 0 && previous.meta == event.Meta:
+		return
+
+	default:
+		s.sources[event.URI] = snapshotSource{
+			keys: event.Keys,
+			meta: event.Meta,
+		}
+	}
+
+	if err := s.save(); err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// save writes the current sources to the snapshot file.  This method must be called
+// under the lock.
+func (s *snapshotter) save() error {
+	sf := snapshotFile{
+		SavedAt: s.clock.Now(),
+		Sources: make([]snapshotFileSource, 0, len(s.sources)),
+	}
+
+	for uri, ss := range s.sources {
+		data, err := marshalSnapshotKeys(ss.keys)
+		if err != nil {
+			return fmt.Errorf("Unable to persist keys for %s: %w", uri, err)
+		}
+
+		sf.Sources = append(sf.Sources, snapshotFileSource{
+			URI:  uri,
+			Meta: ss.meta,
+			Keys: data,
+		})
+	}
+
+	sort.Slice(sf.Sources, func(i, j int) bool {
+		return sf.Sources[i].URI < sf.Sources[j].URI
+	})
+
+	data, err := json.Marshal(sf)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as path, then
+// renames that file to path.  Readers of path never see a partially written file.
+func writeFileAtomic(path string, data []byte) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return
+	}
+
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return
+	}
+
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// marshalSnapshotKeys produces a JWK set containing the public portion of each key.
+// Symmetric keys are skipped.
+func marshalSnapshotKeys(keys Keys) ([]byte, error) {
+	set := jwk.NewSet()
+	for _, k := range keys {
+		jk, err := snapshotJWK(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if jk != nil {
+			if err := set.AddKey(jk); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(set)
+}
+
+// snapshotJWK converts the public portion of a key into a JWK, preserving the attributes
+// that a Parser reads back.  If the key is symmetric, this function returns nil.
+func snapshotJWK(k Key) (jwk.Key, error) {
+	jk, err := jwk.FromRaw(k.Public())
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", k.KeyID(), err)
+	}
+
+	if _, ok := jk.(jwk.SymmetricKey); ok {
+		// never persist secrets
+		return nil, nil
+	}
+
+	err = jk.Set(jwk.KeyIDKey, k.KeyID())
+	if use := k.KeyUsage(); len(use) > 0 {
+		err = multierr.Append(err, jk.Set(jwk.KeyUsageKey, use))
+	}
+
+	if alg := k.Algorithm(); len(alg) > 0 {
+		err = multierr.Append(err, jk.Set(jwk.AlgorithmKey, alg))
+	}
+
+	if ops := k.KeyOperations(); len(ops) > 0 {
+		err = multierr.Append(err, jk.Set(jwk.KeyOpsKey, ops))
+	}
+
+	if certs := k.Certificates(); len(certs) > 0 {
+		var chain cert.Chain
+		for _, c := range certs {
+			err = multierr.Append(err, chain.AddString(base64.StdEncoding.EncodeToString(c.Raw)))
+		}
+
+		err = multierr.Append(err, jk.Set(jwk.X509CertChainKey, &chain))
+	}
+
+	if u := k.CertificateURL(); len(u) > 0 {
+		err = multierr.Append(err, jk.Set(jwk.X509URLKey, u))
+	}
+
+	if t := k.CertificateThumbprint(); len(t) > 0 {
+		err = multierr.Append(err, jk.Set(jwk.X509CertThumbprintKey, t))
+	}
+
+	if t := k.CertificateThumbprintS256(); len(t) > 0 {
+		err = multierr.Append(err, jk.Set(jwk.X509CertThumbprintS256Key, t))
+	}
+
+	if nbf := k.NotBefore(); !nbf.IsZero() {
+		err = multierr.Append(err, jk.Set(JWKNotBeforeField, nbf.Unix()))
+	}
+
+	if exp := k.ExpiresAt(); !exp.IsZero() {
+		err = multierr.Append(err, jk.Set(JWKExpiresAtField, exp.Unix()))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", k.KeyID(), err)
+	}
+
+	return jk, nil
+}
@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/chronon"
+)
+
+type SnapshotterSuite struct {
+	suite.Suite
+
+	path string
+}
+
+func (suite *SnapshotterSuite) SetupTest() {
+	suite.path = filepath.Join(suite.T().TempDir(), "keys.json")
+}
+
+// newKey generates an EC signing key with the given key ID.
+func (suite *SnapshotterSuite) newKey(keyID string) Key {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+
+	jk, err := jwk.FromRaw(pk.Public())
+	suite.Require().NoError(err)
+	suite.Require().NoError(jk.Set(jwk.KeyIDKey, keyID))
+	suite.Require().NoError(jk.Set(jwk.KeyUsageKey, "sig"))
+	suite.Require().NoError(jk.Set(jwk.AlgorithmKey, "ES256"))
+
+	k, err := convertJWKKey(jk)
+	suite.Require().NoError(err)
+	return k
+}
+
+// newSnapshotter creates a Snapshotter using a fake clock, returning both.
+func (suite *SnapshotterSuite) newSnapshotter(kr KeyRing, options ...SnapshotterOption) (Snapshotter, *chronon.FakeClock) {
+	s, err := NewSnapshotter(kr, suite.path, options...)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(s)
+
+	fc := chronon.NewFakeClock(time.Now())
+	s.(*snapshotter).clock = fc
+	return s, fc
+}
+
+func (suite *SnapshotterSuite) TestInvalid() {
+	s, err := NewSnapshotter(nil, "")
+	suite.Nil(s)
+	suite.ErrorIs(err, ErrNoSnapshotKeyRing)
+	suite.ErrorIs(err, ErrNoSnapshotPath)
+}
+
+func (suite *SnapshotterSuite) TestNoSnapshot() {
+	kr := NewKeyRing()
+	s, _ := suite.newSnapshotter(kr)
+
+	n, err := s.Seed()
+	suite.NoError(err)
+	suite.Zero(n)
+	suite.Zero(kr.Len())
+
+	_, _, ok := s.Source("http://getkeys.com/keys")
+	suite.False(ok)
+}
+
+func (suite *SnapshotterSuite) TestSeed() {
+	const uri = "http://getkeys.com/keys"
+
+	var (
+		a    = suite.newKey("A")
+		b    = suite.newKey("B")
+		meta = ContentMeta{Format: MediaTypeJWKSet, ETag: `"v1"`, TTL: time.Hour}
+	)
+
+	// persist the keys from a refresh
+	first, _ := suite.newSnapshotter(NewKeyRing())
+	first.OnRefreshEvent(RefreshEvent{
+		URI:  uri,
+		Keys: Keys{a, b},
+		New:  Keys{a, b},
+		Meta: meta,
+	})
+
+	_, err := os.Stat(suite.path)
+	suite.Require().NoError(err)
+
+	// errors don't overwrite the snapshot
+	first.OnRefreshEvent(RefreshEvent{
+		URI: uri,
+		Err: ErrKeyNotFound,
+	})
+
+	// seed a new KeyRing, as if after a restart
+	kr := NewKeyRing()
+	s, _ := suite.newSnapshotter(kr, WithMaxSnapshotAge(time.Hour))
+	n, err := s.Seed()
+	suite.Require().NoError(err)
+	suite.Equal(2, n)
+
+	seeded := KeyOrigin{Kind: OriginSnapshot, URI: uri}
+	for _, expected := range []Key{a, b} {
+		k, ok := kr.Get(expected.KeyID())
+		suite.Require().True(ok)
+		suite.True(expected.Public().(*ecdsa.PublicKey).Equal(k.Public()))
+		suite.Equal("sig", k.KeyUsage())
+		suite.Equal("ES256", k.Algorithm())
+		suite.Equal([]KeyOrigin{seeded}, kr.Origins(expected.KeyID()))
+	}
+
+	keys, actualMeta, ok := s.Source(uri)
+	suite.True(ok)
+	suite.Equal([]string{"A", "B"}, keys.AppendKeyIDs(nil))
+	suite.Equal(meta.ETag, actualMeta.ETag)
+	suite.Equal(meta.TTL, actualMeta.TTL)
+
+	// the first successful refresh replaces the seeded keys
+	kr.OnRefreshEvent(RefreshEvent{
+		URI:  uri,
+		Keys: Keys{a},
+		New:  Keys{a},
+	})
+
+	suite.Equal(1, kr.Len())
+	suite.Equal([]KeyOrigin{{Kind: OriginRefresh, URI: uri}}, kr.Origins("A"))
+	_, ok = kr.Get("B")
+	suite.False(ok)
+}
+
+func (suite *SnapshotterSuite) TestMaxAge() {
+	const uri = "http://getkeys.com/keys"
+
+	var (
+		a         = suite.newKey("A")
+		first, fc = suite.newSnapshotter(NewKeyRing())
+	)
+
+	first.OnRefreshEvent(RefreshEvent{
+		URI:  uri,
+		Keys: Keys{a},
+		New:  Keys{a},
+	})
+
+	kr := NewKeyRing()
+	s, _ := suite.newSnapshotter(kr, WithMaxSnapshotAge(time.Hour))
+	s.(*snapshotter).clock = chronon.NewFakeClock(fc.Now().Add(2 * time.Hour))
+
+	n, err := s.Seed()
+	suite.ErrorIs(err, ErrSnapshotTooOld)
+	suite.Zero(n)
+	suite.Zero(kr.Len())
+}
+
+func (suite *SnapshotterSuite) TestRemovedSource() {
+	const uri = "http://getkeys.com/keys"
+
+	var (
+		a     = suite.newKey("A")
+		errCh = make(chan error, 1)
+		s, _  = suite.newSnapshotter(
+			NewKeyRing(),
+			WithSnapshotErrorHandler(func(err error) { errCh <- err }),
+		)
+	)
+
+	s.OnRefreshEvent(RefreshEvent{
+		URI:  uri,
+		Keys: Keys{a},
+		New:  Keys{a},
+	})
+
+	_, _, ok := s.Source(uri)
+	suite.True(ok)
+
+	// removing a source from a Refresher dispatches its keys as deleted
+	s.OnRefreshEvent(RefreshEvent{
+		URI:     uri,
+		Deleted: Keys{a},
+	})
+
+	_, _, ok = s.Source(uri)
+	suite.False(ok)
+	suite.Empty(errCh)
+
+	kr := NewKeyRing()
+	seeder, _ := suite.newSnapshotter(kr)
+	n, err := seeder.Seed()
+	suite.NoError(err)
+	suite.Zero(n)
+}
+
+func TestSnapshotter(t *testing.T) {
+	suite.Run(t, new(SnapshotterSuite))
+}
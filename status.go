@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clortho
+
+import (
+	"sync"
+	"time"
+)
+
+// SourceStatus is a snapshot of the state of a single refresh source.
+type SourceStatus struct {
+	// URI is the refresh source's URI.
+	URI string
+
+	// Required indicates whether this source must sync before the Refresher is ready.
+	// This will be true if either the source itself or the Refresher requires it.
+	Required bool
+
+	// LastAttempt is when the source was last fetched.  This will be the zero time
+	// if no fetch has been attempted.
+	LastAttempt time.Time
+
+	// LastSuccess is when the source was last fetched without an error.  This will be
+	// the zero time if no fetch has succeeded.
+	LastSuccess time.Time
+
+	// LastError is the error from the most recent fetch.  This will be nil if the most
+	// recent fetch succeeded or if no fetch has been attempted.
+	LastError error
+
+	// KeyIDs are the key identifiers currently held for this source, sorted.
+	KeyIDs []string
+
+	// Meta is the ContentMeta from the last successful fetch.
+	Meta ContentMeta
+
+	// NextRefresh is when the source is next scheduled to be fetched.  This will be the
+	// zero time if the Refresher is not running or the first fetch hasn't completed.
+	NextRefresh time.Time
+}
+
+// Synced tests if this source has been successfully fetched at least once.
+func (ss SourceStatus) Synced() bool {
+	return !ss.LastSuccess.IsZero()
+}
+
+// RefresherStatus is a snapshot of the state of a Refresher.
+type RefresherStatus struct {
+	// Running indicates whether the Refresher has been started.
+	Running bool
+
+	// Sources holds the status of each refresh source, sorted by URI.
+	Sources []SourceStatus
+}
+
+// Ready tests if the Refresher is running and each required source has synced.
+func (rs RefresherStatus) Ready() bool {
+	if !rs.Running {
+		return false
+	}
+
+	for _, ss := range rs.Sources {
+		if ss.Required && !ss.Synced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sourceStatus is the concurrency-safe, mutable status for a refresh task.
+type sourceStatus struct {
+	lock   sync.Mutex
+	status SourceStatus
+
+	// lastKeys are the keys from the most recent event
+	lastKeys Keys
+}
+
+// setRequired updates whether the source is required.
+func (ss *sourceStatus) setRequired(required bool) {
+	ss.lock.Lock()
+	ss.status.Required = required
+	ss.lock.Unlock()
+}
+
+// keys returns the keys from the most recent event.
+func (ss *sourceStatus) keys() (k Keys) {
+	ss.lock.Lock()
+	k = append(k, ss.lastKeys...)
+	ss.lock.Unlock()
+	return
+}
+
+// onAttempt records the start of a fetch.
+func (ss *sourceStatus) onAttempt(now time.Time) {
+	ss.lock.Lock()
+	ss.status.LastAttempt = now
+	ss.lock.Unlock()
+}
+
+// onResult records the outcome of a fetch along with the next scheduled refresh.
+func (ss *sourceStatus) onResult(now time.Time, event RefreshEvent, meta ContentMeta, next time.Time) {
+	keyIDs := make([]string, 0, len(event.Keys))
+	for _, k := range event.Keys {
+		keyIDs = append(keyIDs, k.KeyID())
+	}
+
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	if event.Err == nil {
+		ss.status.LastSuccess = now
+	}
+
+	ss.status.LastError = event.Err
+	ss.status.KeyIDs = keyIDs
+	ss.lastKeys = event.Keys
+	ss.status.Meta = meta
+	ss.status.NextRefresh = next
+}
+
+// snapshot returns a copy of the current status.
+func (ss *sourceStatus) snapshot() (s SourceStatus) {
+	ss.lock.Lock()
+	s = ss.status
+	ss.lock.Unlock()
+
+	s.KeyIDs = append([]string(nil), s.KeyIDs...)
+	return
+}